@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/abi_eth/fourbyte"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// batchWindowSize is the maximum number of records read ahead of the written results,
+	// it bounds the memory usage of the reorder buffer.
+	batchWindowSize = 4096
+	// batchMaxLineSize is the maximum size of the input record.
+	batchMaxLineSize = 64 << 20
+)
+
+const batchUsage = `Usage: abidump batch [flags] [file]
+
+Decodes the newline-delimited records from the file or stdin concurrently and writes
+the JSONL results in the order of the records. Each record is either the hex call data
+or the JSON object {"to": "0x...", "input": "0x..."}, "data" may be used instead of "input".
+
+Flags:
+`
+
+// batchRecord is the JSON record of the batch input, e.g. the transaction exported from the node.
+type batchRecord struct {
+	To    *fourbyte.Address `json:"to"`
+	Input string            `json:"input"`
+	Data  string            `json:"data"`
+}
+
+// batchOutput is the JSONL record of the batch output, Line is the number of the input line.
+type batchOutput struct {
+	Line   int                       `json:"line"`
+	Result *fourbyte.DecodedCallData `json:"result,omitempty"`
+	Error  string                    `json:"error,omitempty"`
+}
+
+// runBatch executes the batch subcommand and returns the exit code.
+func runBatch(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("abidump batch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, batchUsage)
+		flags.PrintDefaults()
+	}
+	var (
+		workers    = flags.Int("workers", runtime.NumCPU(), "number of decoding workers")
+		signatures stringsFlag
+	)
+	flags.Var(&signatures, "signatures", "additional signatures file in the 4byte.json format, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *workers < 1 || flags.NArg() > 1 {
+		flags.Usage()
+		return exitUsage
+	}
+	db, err := newDatabase(signatures)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	input := stdin
+	if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		defer f.Close()
+		input = f
+	}
+
+	out := bufio.NewWriter(stdout)
+	failed, err := decodeBatch(db, input, out, *workers)
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	if failed != 0 {
+		fmt.Fprintf(stderr, "failed to decode %d records\n", failed)
+		return exitDecodeFailed
+	}
+	return exitOK
+}
+
+// batchJob is the record of the batch which is being decoded, done is closed when the result is ready.
+type batchJob struct {
+	line   int
+	call   fourbyte.BatchCall
+	result fourbyte.BatchResult
+	done   chan struct{}
+}
+
+// decodeBatch decodes the records by the long-lived pool of workers and returns the number of failed records.
+// The reader feeds the workers and the reorder buffer, the writer takes the jobs from the buffer in the order
+// of records and waits for their results, so a slow record stalls the output only, not the decoding of the
+// following records until the buffer is full.
+func decodeBatch(db *fourbyte.Database, r io.Reader, w io.Writer, workers int) (int, error) {
+	var (
+		jobs    = make(chan *batchJob)
+		ordered = make(chan *batchJob, batchWindowSize)
+		stopped = make(chan struct{}) // closed by the writer when it fails
+		written = make(chan struct{}) // closed when the writer is done
+		pool    sync.WaitGroup
+
+		failed   int
+		writeErr error
+	)
+	for i := 0; i < workers; i++ {
+		pool.Add(1)
+		go func() {
+			defer pool.Done()
+			for job := range jobs {
+				job.result = db.ParseBatchCall(job.call)
+				close(job.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(written)
+		encoder := json.NewEncoder(w)
+		for job := range ordered {
+			if writeErr != nil {
+				// drain the buffer, the job may be never dispatched to the workers
+				continue
+			}
+			<-job.done
+			output := batchOutput{Line: job.line}
+			if job.result.Err != nil {
+				output.Error = job.result.Err.Error()
+				failed++
+			} else {
+				output.Result = job.result.Decoded
+			}
+			if err := encoder.Encode(output); err != nil {
+				writeErr = err
+				close(stopped)
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), batchMaxLineSize)
+	line := 0
+read:
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		job := &batchJob{line: line, done: make(chan struct{})}
+		job.call, job.result.Err = parseBatchRecord(text)
+		select {
+		case ordered <- job:
+		case <-stopped:
+			break read
+		}
+		if job.result.Err != nil {
+			close(job.done)
+			continue
+		}
+		select {
+		case jobs <- job:
+		case <-stopped:
+			break read
+		}
+	}
+	close(jobs)
+	pool.Wait()
+	close(ordered)
+	<-written
+
+	if writeErr != nil {
+		return failed, writeErr
+	}
+	if err := scanner.Err(); err != nil {
+		return failed, fmt.Errorf("failed to read line %d: %v", line+1, err)
+	}
+	return failed, nil
+}
+
+// parseBatchRecord parses the hex call data or the JSON record.
+func parseBatchRecord(text string) (fourbyte.BatchCall, error) {
+	if !strings.HasPrefix(text, "{") {
+		data, err := decodeHex(text)
+		if err != nil {
+			return fourbyte.BatchCall{}, fmt.Errorf("invalid hex data: %v", err)
+		}
+		return fourbyte.BatchCall{Data: data}, nil
+	}
+	var record batchRecord
+	if err := json.Unmarshal([]byte(text), &record); err != nil {
+		return fourbyte.BatchCall{}, fmt.Errorf("invalid JSON record: %v", err)
+	}
+	input := record.Input
+	if input == "" {
+		input = record.Data
+	}
+	data, err := decodeHex(input)
+	if err != nil {
+		return fourbyte.BatchCall{}, fmt.Errorf("invalid hex data: %v", err)
+	}
+	return fourbyte.BatchCall{To: record.To, Data: data}, nil
+}
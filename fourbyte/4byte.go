@@ -1,28 +1,86 @@
 package fourbyte
 
 import (
+	"compress/gzip"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
+	"io"
 	"strings"
+	"sync"
 )
 
-var __4byteJson = map[string]string{
-	"a9059cbb": "transfer(address,uint256)",
-	"23b872dd": "transferFrom(address,address,uint256)",
+//go:generate go run gen.go
+
+var (
+	embeddedOnce       sync.Once
+	embeddedSignatures map[string][]string
+	embeddedErr        error
+)
+
+// embedded4byte decompresses and parses the embedded signatures only once,
+// the result is shared between all databases, so it must not be modified.
+func embedded4byte() (map[string][]string, error) {
+	embeddedOnce.Do(func() {
+		gz, err := gzip.NewReader(strings.NewReader(embedded4byteJsonGz))
+		if err != nil {
+			embeddedErr = errors.Wrap(err, "failed to decompress embedded 4byte signatures")
+			return
+		}
+		defer gz.Close()
+		embeddedSignatures, embeddedErr = Load4byteJSON(gz)
+	})
+	return embeddedSignatures, embeddedErr
+}
+
+// Load4byteJSON loads the signatures in the 4byte.json format (hex selector → signature).
+// Each selector maps either to a single signature or to the list of colliding signatures,
+// e.g. {"a9059cbb": ["transfer(address,uint256)", "many_msg_babbage(bytes1)"]}.
+// Selectors are normalized to the lowercase hex representation without the "0x" prefix.
+// Note, the signatures aren't validated, it's assumed the caller will do.
+func Load4byteJSON(r io.Reader) (map[string][]string, error) {
+	var signatures map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&signatures); err != nil {
+		return nil, errors.Wrap(err, "failed to parse 4byte json")
+	}
+	normalized := make(map[string][]string, len(signatures))
+	for hexSelector, raw := range signatures {
+		var candidates []string
+		if err := json.Unmarshal(raw, &candidates); err != nil {
+			var signature string
+			if err := json.Unmarshal(raw, &signature); err != nil {
+				return nil, errors.Errorf("invalid signatures for selector %q: %s", hexSelector, raw)
+			}
+			candidates = []string{signature}
+		}
+		var selector Selector
+		if err := selector.FromHex(strings.TrimPrefix(hexSelector, "0x")); err != nil {
+			return nil, errors.Wrapf(err, "invalid selector %q for signatures %q", hexSelector, candidates)
+		}
+		normalized[selector.Hex()] = append(normalized[selector.Hex()], candidates...)
+	}
+	return normalized, nil
 }
 
 const (
 	selectorLen = 4
-
-	addressSize = 20
-	uint256Size = 256
 )
 
 const (
 	erc20TransferSignature     Signature = "transfer(address,uint256)"
 	erc20TransferFromSignature Signature = "transferFrom(address,address,uint256)"
+	erc20ApproveSignature      Signature = "approve(address,uint256)"
+	erc20AllowanceSignature    Signature = "allowance(address,address)"
+	erc20BalanceOfSignature    Signature = "balanceOf(address)"
+	erc20TotalSupplySignature  Signature = "totalSupply()"
+	erc20NameSignature         Signature = "name()"
+	erc20SymbolSignature       Signature = "symbol()"
+	erc20DecimalsSignature     Signature = "decimals()"
+
+	erc20TransferEventSignature Signature = "Transfer(address,address,uint256)"
+	erc20ApprovalEventSignature Signature = "Approval(address,address,uint256)"
 )
 
 type Signature string
@@ -44,6 +102,13 @@ func (s Signature) Selector() Selector {
 	return NewSelector(s)
 }
 
+// Hash returns the Keccak256 hash of the signature, e.g. the event topic.
+func (s Signature) Hash() Hash {
+	var hash Hash
+	copy(hash[:], crypto.Keccak256([]byte(s)))
+	return hash
+}
+
 type Selector [selectorLen]byte
 
 func NewSelector(sig Signature) Selector {
@@ -72,59 +137,111 @@ func (s *Selector) FromHex(hexSelector string) error {
 	return nil
 }
 
+// Hash represents the 32 byte Keccak256 hash of arbitrary data.
+type Hash [32]byte
+
+func (h Hash) String() string {
+	return h.Hex()
+}
+
+func (h Hash) Hex() string {
+	return hex.EncodeToString(h[:])
+}
+
 var erc20Methods = map[Selector]Method{
-	erc20TransferSignature.Selector(): {
-		RawName: "transfer",
-		Type:    Callable,
-		Inputs: Arguments{
-			Argument{
-				Name: "_to",
-				Type: Type{
-					Size:       addressSize,
-					T:          AddressTy,
-					stringKind: "address",
-				},
-			},
-			Argument{
-				Name: "_value",
-				Type: Type{
-					Size:       uint256Size,
-					T:          UintTy,
-					stringKind: "uint256",
-				},
-			},
+	erc20TransferSignature.Selector(): NewMethod("transfer", Callable,
+		Arguments{
+			{Name: "_to", Type: mustNewType("address")},
+			{Name: "_value", Type: mustNewType("uint256")},
 		},
-		Sig: erc20TransferSignature,
-	},
-	erc20TransferFromSignature.Selector(): {
-		RawName: "transferFrom",
-		Type:    Callable,
-		Inputs: Arguments{
-			Argument{
-				Name: "_from",
-				Type: Type{
-					Size:       addressSize,
-					T:          AddressTy,
-					stringKind: "address",
-				},
-			},
-			Argument{
-				Name: "_to",
-				Type: Type{
-					Size:       addressSize,
-					T:          AddressTy,
-					stringKind: "address",
-				},
+		Arguments{{Name: "success", Type: mustNewType("bool")}},
+	),
+	erc20TransferFromSignature.Selector(): NewMethod("transferFrom", Callable,
+		Arguments{
+			{Name: "_from", Type: mustNewType("address")},
+			{Name: "_to", Type: mustNewType("address")},
+			{Name: "_value", Type: mustNewType("uint256")},
+		},
+		Arguments{{Name: "success", Type: mustNewType("bool")}},
+	),
+}
+
+// erc20ABIMethods holds all methods of the ERC20 standard with their outputs, it's used for the decoding
+// of the return data, e.g. the balanceOf or decimals eth_call results, see ERC20ABI.
+// Only transfers are recognized as ERC20 calls, see erc20Methods and Method.IsERC20.
+var erc20ABIMethods = func() map[Selector]Method {
+	methods := map[Selector]Method{
+		erc20ApproveSignature.Selector(): NewMethod("approve", Callable,
+			Arguments{
+				{Name: "_spender", Type: mustNewType("address")},
+				{Name: "_value", Type: mustNewType("uint256")},
 			},
-			Argument{
-				Name: "_value",
-				Type: Type{
-					Size:       uint256Size,
-					T:          UintTy,
-					stringKind: "uint256",
-				},
+			Arguments{{Name: "success", Type: mustNewType("bool")}},
+		),
+		erc20AllowanceSignature.Selector(): NewMethod("allowance", Callable,
+			Arguments{
+				{Name: "_owner", Type: mustNewType("address")},
+				{Name: "_spender", Type: mustNewType("address")},
 			},
+			Arguments{{Name: "remaining", Type: mustNewType("uint256")}},
+		),
+		erc20BalanceOfSignature.Selector(): NewMethod("balanceOf", Callable,
+			Arguments{{Name: "_owner", Type: mustNewType("address")}},
+			Arguments{{Name: "balance", Type: mustNewType("uint256")}},
+		),
+		erc20TotalSupplySignature.Selector(): NewMethod("totalSupply", Callable,
+			nil,
+			Arguments{{Type: mustNewType("uint256")}},
+		),
+		// name, symbol and decimals are optional methods of the ERC20 standard
+		erc20NameSignature.Selector(): NewMethod("name", Callable,
+			nil,
+			Arguments{{Type: mustNewType("string")}},
+		),
+		erc20SymbolSignature.Selector(): NewMethod("symbol", Callable,
+			nil,
+			Arguments{{Type: mustNewType("string")}},
+		),
+		erc20DecimalsSignature.Selector(): NewMethod("decimals", Callable,
+			nil,
+			Arguments{{Type: mustNewType("uint8")}},
+		),
+	}
+	for selector, method := range erc20Methods {
+		methods[selector] = method
+	}
+	return methods
+}()
+
+// ERC20ABI returns the ABI of the ERC20 standard with the methods outputs and events.
+// The returned ABI can be modified by the caller.
+func ERC20ABI() *ABI {
+	abi := &ABI{
+		Methods: make(map[Selector]Method, len(erc20ABIMethods)),
+		Events:  make(map[Hash]Event, len(erc20Events)),
+	}
+	for selector, method := range erc20ABIMethods {
+		abi.Methods[selector] = method
+	}
+	for id, event := range erc20Events {
+		abi.Events[id] = event
+	}
+	return abi
+}
+
+var erc20Events = map[Hash]Event{
+	erc20TransferEventSignature.Hash(): NewEvent("Transfer", false,
+		Arguments{
+			{Name: "_from", Type: mustNewType("address"), Indexed: true},
+			{Name: "_to", Type: mustNewType("address"), Indexed: true},
+			{Name: "_value", Type: mustNewType("uint256")},
+		},
+	),
+	erc20ApprovalEventSignature.Hash(): NewEvent("Approval", false,
+		Arguments{
+			{Name: "_owner", Type: mustNewType("address"), Indexed: true},
+			{Name: "_spender", Type: mustNewType("address"), Indexed: true},
+			{Name: "_value", Type: mustNewType("uint256")},
 		},
-		Sig: erc20TransferFromSignature,
-	},
+	),
 }
@@ -1,18 +1,146 @@
 package fourbyte
 
 import (
+	"encoding/json"
 	"github.com/pkg/errors"
+	"io"
 )
 
+// ABI holds information about a contract's context and available
+// invokable methods. It will allow you to type check function calls and
+// packs data accordingly.
 type ABI struct {
-	Methods map[Selector]Method
+	Constructor Method
+	Methods     map[Selector]Method
+	Events      map[Hash]Event
+	Errors      map[Selector]Error
+
+	// Additional "special" functions introduced in solidity v0.6.0.
+	// It's separated from the original default fallback. Each contract
+	// can only define one fallback and receive function.
+	Fallback Method // Note it's also used to represent legacy fallback before v0.6.0
+	Receive  Method
+
+	// Verifier is the verifier function of the Waves dApp, see VerifierName.
+	Verifier Method
+
+	// Ride reports whether the ABI belongs to the Ride dApp, see NewRideABI. The call data for such
+	// contract is decoded in the Ride dApp context: the trailing payments argument of any candidate method
+	// is treated as payments, see Method.Payments.
+	Ride bool
+}
+
+// JSON returns a parsed ABI interface and error if it failed.
+// It accepts the standard Solidity JSON ABI produced by the compiler or Etherscan.
+func JSON(reader io.Reader) (*ABI, error) {
+	dec := json.NewDecoder(reader)
+
+	var abi ABI
+	if err := dec.Decode(&abi); err != nil {
+		return nil, err
+	}
+	return &abi, nil
 }
 
-// MethodById looks up a method by the 4-byte id,
-// returns nil if none found.
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (abi *ABI) UnmarshalJSON(data []byte) error {
+	var fields []struct {
+		Type      string
+		Name      string
+		Inputs    []Argument
+		Outputs   []Argument
+		Anonymous bool
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	abi.Methods = make(map[Selector]Method)
+	abi.Events = make(map[Hash]Event)
+	abi.Errors = make(map[Selector]Error)
+	for _, field := range fields {
+		switch field.Type {
+		case "constructor":
+			abi.Constructor = NewMethod("", Constructor, field.Inputs, nil)
+		case "function", "": // empty defaults to function according to the abi spec
+			method := NewMethod(field.Name, Callable, field.Inputs, field.Outputs)
+			selector := method.Sig.Selector()
+			if _, ok := abi.Methods[selector]; ok {
+				return errors.Errorf("duplicate method %q with selector %s", method.Sig, selector)
+			}
+			abi.Methods[selector] = method
+		case "fallback":
+			// New introduced function type in v0.6.0, check more detail
+			// here https://solidity.readthedocs.io/en/v0.6.0/contracts.html#fallback-function
+			if abi.HasFallback() {
+				return errors.New("only single fallback is allowed")
+			}
+			abi.Fallback = NewMethod("", Fallback, nil, nil)
+		case "receive":
+			if abi.HasReceive() {
+				return errors.New("only single receive is allowed")
+			}
+			abi.Receive = NewMethod("", Receive, nil, nil)
+		case "event":
+			event := NewEvent(field.Name, field.Anonymous, field.Inputs)
+			if _, ok := abi.Events[event.ID]; ok {
+				return errors.Errorf("duplicate event %q", event.Sig)
+			}
+			abi.Events[event.ID] = event
+		case "error":
+			abiError := NewError(field.Name, field.Inputs)
+			selector := abiError.Sig.Selector()
+			if _, ok := abi.Errors[selector]; ok {
+				return errors.Errorf("duplicate error %q with selector %s", abiError.Sig, selector)
+			}
+			abi.Errors[selector] = abiError
+		default:
+			return errors.Errorf("abi: could not recognize type %v of field %v", field.Type, field.Name)
+		}
+	}
+	return nil
+}
+
+// MethodById looks up a method by the 4-byte id, the verifier is looked up by the reserved selector,
+// returns error if none found.
 func (abi *ABI) MethodById(selector Selector) (Method, error) {
 	if method, ok := abi.Methods[selector]; ok {
 		return method, nil
 	}
+	if abi.HasVerifier() && abi.Verifier.Sig.Selector() == selector {
+		return abi.Verifier, nil
+	}
 	return Method{}, errors.Errorf("no method with id: %#x", selector[:])
 }
+
+// EventById looks up an event by the topic id,
+// returns error if none found.
+func (abi *ABI) EventById(topic Hash) (Event, error) {
+	if event, ok := abi.Events[topic]; ok {
+		return event, nil
+	}
+	return Event{}, errors.Errorf("no event with id: %#x", topic[:])
+}
+
+// ErrorById looks up a custom error by the 4-byte id,
+// returns error if none found.
+func (abi *ABI) ErrorById(selector Selector) (Error, error) {
+	if abiError, ok := abi.Errors[selector]; ok {
+		return abiError, nil
+	}
+	return Error{}, errors.Errorf("no error with id: %#x", selector[:])
+}
+
+// HasFallback returns an indicator whether a fallback function is included.
+func (abi *ABI) HasFallback() bool {
+	return abi.Fallback.Type == Fallback
+}
+
+// HasReceive returns an indicator whether a receive function is included.
+func (abi *ABI) HasReceive() bool {
+	return abi.Receive.Type == Receive
+}
+
+// HasVerifier returns an indicator whether a verifier function is included.
+func (abi *ABI) HasVerifier() bool {
+	return abi.Verifier.Type == Verifier
+}
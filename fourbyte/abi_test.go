@@ -0,0 +1,108 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const testJSONABI = `[
+	{"type": "constructor", "inputs": [{"name": "_supply", "type": "uint256", "internalType": "uint256"}], "stateMutability": "nonpayable"},
+	{"type": "fallback", "stateMutability": "payable"},
+	{"type": "receive", "stateMutability": "payable"},
+	{
+		"type": "function",
+		"name": "transfer",
+		"inputs": [
+			{"name": "_to", "type": "address", "internalType": "address"},
+			{"name": "_value", "type": "uint256", "internalType": "uint256"}
+		],
+		"outputs": [{"name": "", "type": "bool", "internalType": "bool"}],
+		"stateMutability": "nonpayable"
+	},
+	{
+		"type": "function",
+		"name": "submit",
+		"inputs": [
+			{
+				"name": "orders",
+				"type": "tuple[]",
+				"internalType": "struct Exchange.Order[]",
+				"components": [
+					{"name": "maker", "type": "address", "internalType": "address"},
+					{"name": "amounts", "type": "uint256[2]", "internalType": "uint256[2]"},
+					{"name": "salt", "type": "bytes32", "internalType": "bytes32"}
+				]
+			}
+		],
+		"outputs": []
+	},
+	{
+		"type": "event",
+		"name": "Transfer",
+		"anonymous": false,
+		"inputs": [
+			{"name": "from", "type": "address", "indexed": true},
+			{"name": "to", "type": "address", "indexed": true},
+			{"name": "value", "type": "uint256", "indexed": false}
+		]
+	},
+	{
+		"type": "error",
+		"name": "InsufficientBalance",
+		"inputs": [
+			{"name": "available", "type": "uint256"},
+			{"name": "required", "type": "uint256"}
+		]
+	}
+]`
+
+func TestJSON(t *testing.T) {
+	abi, err := JSON(strings.NewReader(testJSONABI))
+	require.NoError(t, err)
+
+	require.Equal(t, Constructor, abi.Constructor.Type)
+	require.Len(t, abi.Constructor.Inputs, 1)
+	require.True(t, abi.HasFallback())
+	require.True(t, abi.HasReceive())
+
+	require.Len(t, abi.Methods, 2)
+	transfer, err := abi.MethodById(erc20TransferSignature.Selector())
+	require.NoError(t, err)
+	require.Equal(t, erc20TransferSignature, transfer.Sig)
+
+	submitSig := Signature("submit((address,uint256[2],bytes32)[])")
+	submit, err := abi.MethodById(submitSig.Selector())
+	require.NoError(t, err)
+	require.Equal(t, submitSig, submit.Sig)
+	orders := submit.Inputs[0].Type
+	require.Equal(t, SliceTy, orders.T)
+	require.Equal(t, "ExchangeOrder", orders.Elem.TupleRawName)
+	require.Equal(t, []string{"maker", "amounts", "salt"}, orders.Elem.TupleRawNames)
+	require.Equal(t, "Amounts", orders.Elem.TupleType.Field(1).Name)
+
+	transferEventSig := Signature("Transfer(address,address,uint256)")
+	event, err := abi.EventById(transferEventSig.Hash())
+	require.NoError(t, err)
+	require.Equal(t, transferEventSig, event.Sig)
+	require.True(t, event.Inputs[0].Indexed)
+	require.False(t, event.Inputs[2].Indexed)
+	require.Equal(t, "event Transfer(address indexed from, address indexed to, uint256 value)", event.String())
+
+	insufficientBalanceSig := Signature("InsufficientBalance(uint256,uint256)")
+	abiError, err := abi.ErrorById(insufficientBalanceSig.Selector())
+	require.NoError(t, err)
+	require.Equal(t, insufficientBalanceSig, abiError.Sig)
+}
+
+func TestJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`[{"type": "unknown"}]`,
+		`[{"type": "function", "name": "foo", "inputs": [{"type": "uint7"}]}]`,
+		`[{"type": "function", "name": "foo"}, {"type": "function", "name": "foo"}]`,
+	} {
+		_, err := JSON(strings.NewReader(data))
+		require.Error(t, err, data)
+	}
+}
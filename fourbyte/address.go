@@ -0,0 +1,128 @@
+package fourbyte
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"strings"
+)
+
+const (
+	// AddressLength is the length of the Ethereum address in bytes.
+	AddressLength = 20
+	// WavesAddressLength is the length of the Waves address in bytes:
+	// version, chain ID, the Ethereum address and the checksum.
+	WavesAddressLength = 1 + 1 + AddressLength + wavesAddressChecksumLength
+
+	wavesAddressVersion        = 1
+	wavesAddressChecksumLength = 4
+)
+
+// Address is the 20 byte Ethereum address.
+type Address [AddressLength]byte
+
+// BytesToAddress returns the address with the value of b. If b is larger than AddressLength,
+// b will be cropped from the left.
+func BytesToAddress(b []byte) Address {
+	var a Address
+	if len(b) > len(a) {
+		b = b[len(b)-AddressLength:]
+	}
+	copy(a[AddressLength-len(b):], b)
+	return a
+}
+
+// ParseAddress parses the hex address with the optional 0x prefix. Mixed case addresses
+// must have the valid EIP-55 checksum, all lower or all upper case addresses are accepted as is.
+func ParseAddress(s string) (Address, error) {
+	hexAddress := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(hexAddress) != 2*AddressLength {
+		return Address{}, errors.Errorf("invalid address %q: hex length should be %d", s, 2*AddressLength)
+	}
+	bts, err := hex.DecodeString(hexAddress)
+	if err != nil {
+		return Address{}, errors.Wrapf(err, "invalid address %q", s)
+	}
+	a := BytesToAddress(bts)
+	if hexAddress != strings.ToLower(hexAddress) && hexAddress != strings.ToUpper(hexAddress) {
+		if checksummed := a.Hex()[2:]; checksummed != hexAddress {
+			return Address{}, errors.Errorf("invalid address %q: bad EIP-55 checksum, expected %q", s, "0x"+checksummed)
+		}
+	}
+	return a, nil
+}
+
+// Bytes returns the address bytes.
+func (a Address) Bytes() []byte {
+	return a[:]
+}
+
+// Hex returns the EIP-55 checksummed hex representation of the address with the 0x prefix.
+func (a Address) Hex() string {
+	buf := []byte(hex.EncodeToString(a[:]))
+	hash := crypto.Keccak256(buf)
+	for i, c := range buf {
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0xf >= 8 {
+			buf[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(buf)
+}
+
+func (a Address) String() string {
+	return a.Hex()
+}
+
+// MarshalText implements encoding.TextMarshaler, the address is encoded as the EIP-55 hex string.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see ParseAddress.
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// WavesAddressBytes returns the Waves address bytes of the Ethereum address for the given chain ID.
+func (a Address) WavesAddressBytes(chainID byte) []byte {
+	bts := make([]byte, 0, WavesAddressLength)
+	bts = append(bts, wavesAddressVersion, chainID)
+	bts = append(bts, a[:]...)
+	return append(bts, wavesAddressChecksum(bts)...)
+}
+
+// AddressFromWavesBytes returns the Ethereum address of the Waves address bytes.
+// The address version, chain ID and checksum are validated.
+func AddressFromWavesBytes(bts []byte, chainID byte) (Address, error) {
+	if len(bts) != WavesAddressLength {
+		return Address{}, errors.Errorf("invalid waves address length %d, expected %d", len(bts), WavesAddressLength)
+	}
+	if bts[0] != wavesAddressVersion {
+		return Address{}, errors.Errorf("unsupported waves address version %d", bts[0])
+	}
+	if bts[1] != chainID {
+		return Address{}, errors.Errorf("waves address chain ID %d doesn't match the expected %d", bts[1], chainID)
+	}
+	body := bts[:WavesAddressLength-wavesAddressChecksumLength]
+	if !bytes.Equal(wavesAddressChecksum(body), bts[len(body):]) {
+		return Address{}, errors.New("invalid waves address checksum")
+	}
+	return BytesToAddress(body[2:]), nil
+}
+
+// wavesAddressChecksum returns the first bytes of the Keccak256(Blake2b256(body)) hash.
+func wavesAddressChecksum(body []byte) []byte {
+	hash := blake2b.Sum256(body)
+	return crypto.Keccak256(hash[:])[:wavesAddressChecksumLength]
+}
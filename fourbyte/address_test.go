@@ -0,0 +1,82 @@
+package fourbyte
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestAddressHex(t *testing.T) {
+	// test vectors from EIP-55
+	for _, hexAddress := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		a, err := ParseAddress(strings.ToLower(hexAddress))
+		require.NoError(t, err)
+		require.Equal(t, hexAddress, a.Hex())
+
+		parsed, err := ParseAddress(hexAddress)
+		require.NoError(t, err)
+		require.Equal(t, a, parsed)
+		parsed, err = ParseAddress(strings.ToUpper(hexAddress[2:]))
+		require.NoError(t, err)
+		require.Equal(t, a, parsed)
+	}
+}
+
+func TestParseAddressInvalid(t *testing.T) {
+	for _, hexAddress := range []string{
+		"",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed00",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg",
+		// bad checksum
+		"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	} {
+		_, err := ParseAddress(hexAddress)
+		require.Error(t, err, hexAddress)
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	a := mustParseAddress(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	data, err := json.Marshal(map[string]Address{"to": a})
+	require.NoError(t, err)
+	require.Equal(t, `{"to":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}`, string(data))
+
+	var decoded map[string]Address
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, a, decoded["to"])
+	require.Error(t, json.Unmarshal([]byte(`{"to":"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}`), &decoded))
+}
+
+func TestAddressWavesBytes(t *testing.T) {
+	const chainID = 'W'
+	a := mustParseAddress(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	bts := a.WavesAddressBytes(chainID)
+	require.Len(t, bts, WavesAddressLength)
+	require.Equal(t, []byte{wavesAddressVersion, chainID}, bts[:2])
+	require.Equal(t, a.Bytes(), bts[2:2+AddressLength])
+
+	converted, err := AddressFromWavesBytes(bts, chainID)
+	require.NoError(t, err)
+	require.Equal(t, a, converted)
+
+	_, err = AddressFromWavesBytes(bts, 'T')
+	require.Error(t, err)
+	_, err = AddressFromWavesBytes(bts[1:], chainID)
+	require.Error(t, err)
+	bts[len(bts)-1] ^= 0xff
+	_, err = AddressFromWavesBytes(bts, chainID)
+	require.Error(t, err)
+}
+
+func mustParseAddress(t *testing.T, hexAddress string) Address {
+	a, err := ParseAddress(hexAddress)
+	require.NoError(t, err)
+	return a
+}
@@ -1,27 +1,70 @@
 package fourbyte
 
 import (
+	"encoding/json"
 	"fmt"
-	"github.com/ethereum/go-ethereum/common"
+	"math"
+	"math/big"
+	"reflect"
 )
 
 type Argument struct {
-	Name string
-	Type Type
+	Name    string
+	Type    Type
+	Indexed bool // indexed is only used by events
 }
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the JSON representation of the argument in the Solidity JSON ABI.
+type ArgumentMarshaling struct {
+	Name         string
+	Type         string
+	InternalType string
+	Components   []ArgumentMarshaling
+	Indexed      bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (argument *Argument) UnmarshalJSON(data []byte) error {
+	var arg ArgumentMarshaling
+	err := json.Unmarshal(data, &arg)
+	if err != nil {
+		return fmt.Errorf("argument json err: %v", err)
+	}
+
+	argument.Type, err = newTypeWithComponents(arg.Type, arg.InternalType, arg.Components)
+	if err != nil {
+		return err
+	}
+	argument.Name = arg.Name
+	argument.Indexed = arg.Indexed
+
+	return nil
+}
+
 // UnpackValues can be used to unpack ABI-encoded hexdata according to the ABI-specification,
 // without supplying a struct to unpack into. Instead, this method returns a list containing the
 // values. An atomic argument will be a list with one element.
+// The payments argument is unpacked like any other argument, see toPayments for its conversion.
 func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
-	// TODO(nickeskov): parse payment tuples
 	retval := make([]interface{}, 0, len(arguments))
 	virtualArgs := 0
 	for index, arg := range arguments {
 		marshalledValue, err := toGoType((index+virtualArgs)*32, arg.Type, data)
-		if arg.Type.T == TupleTy && !isDynamicType(arg.Type) {
+		if arg.Type.T == ArrayTy && !isDynamicType(arg.Type) {
+			// If we have a static array, like [3]uint256, these are coded as
+			// just like uint256,uint256,uint256.
+			// This means that we need to add two 'virtual' arguments when
+			// we count the index from now on.
+			//
+			// Array values nested multiple levels deep are also encoded inline:
+			// [2][3]uint256: uint256,uint256,uint256,uint256,uint256,uint256
+			//
+			// Calculate the full array size to get the correct offset for the next argument.
+			// Decrement it by 1, as the normal index increment is still applied.
+			virtualArgs += getTypeSize(arg.Type)/32 - 1
+		} else if arg.Type.T == TupleTy && !isDynamicType(arg.Type) {
 			// If we have a static tuple, like (uint256, bool, uint256), these are
 			// coded as just like uint256,bool,uint256
 			virtualArgs += getTypeSize(arg.Type)/32 - 1
@@ -34,7 +77,21 @@ func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
 	return retval, nil
 }
 
-// TODO(nickeskov): add ABI spec marshaling
+// Pack performs the operation Go format -> Hexdata according to the ABI-specification.
+// Values must be passed in the order of arguments, tuples are packed from the struct
+// fields in the order of definition. The values returned by UnpackValues can be packed back.
+func (arguments Arguments) Pack(values ...interface{}) ([]byte, error) {
+	if len(values) != len(arguments) {
+		return nil, fmt.Errorf("argument count mismatch: got %d for %d", len(values), len(arguments))
+	}
+	types := make([]*Type, len(arguments))
+	reflectValues := make([]reflect.Value, len(values))
+	for i := range arguments {
+		types[i] = &arguments[i].Type
+		reflectValues[i] = reflect.ValueOf(values[i])
+	}
+	return packTuple(types, reflectValues)
+}
 
 // toGoType parses the output bytes and recursively assigns the value of these bytes
 // into a go type with accordance with the ABI spec.
@@ -73,6 +130,16 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 		return forTupleUnpack(t, output[index:])
 	case SliceTy:
 		return forEachUnpack(t, output[begin:], 0, length)
+	case ArrayTy:
+		if isDynamicType(*t.Elem) {
+			// dynamic elements are encoded at the offset which is stored in-place
+			offset, err := tuplePointsTo(index, output)
+			if err != nil {
+				return nil, err
+			}
+			return forEachUnpack(t, output[offset:], 0, t.Size)
+		}
+		return forEachUnpack(t, output[index:], 0, t.Size)
 	case StringTy: // variable arrays are written at the end of the return bytes
 		return string(output[begin : begin+length]), nil
 	case IntTy, UintTy:
@@ -80,13 +147,94 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 	case BoolTy:
 		return readBool(returnOutput)
 	case AddressTy:
-		// TODO(nickeskov): use our address
-		return common.BytesToAddress(returnOutput), nil
+		return BytesToAddress(returnOutput), nil
 	case BytesTy:
 		return output[begin : begin+length], nil
+	case FixedBytesTy:
+		return ReadFixedBytes(t, returnOutput)
+	case HashTy:
+		var hash Hash
+		copy(hash[:], returnOutput)
+		return hash, nil
 	default:
 		return nil, fmt.Errorf("abi: unknown type %v", t.T)
 	}
 }
 
-// TODO(nickeskov): write 'toRideType' converter
+// toRideType converts the value of the type t produced by toGoType into the Ride VM value model:
+// integers up to 64 bits to Int (with range checks for unsigned ones), wider integers to BigInt,
+// bytes, fixed bytes, hashes and addresses to ByteVector, slices and arrays (including uint8 ones)
+// to List, tuples to Ride tuples.
+func toRideType(t Type, value interface{}) (RideType, error) {
+	v := indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return nil, &RideConversionError{Value: value, Err: ErrRideUnsupportedType}
+	}
+
+	switch t.T {
+	case IntTy, UintTy:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return RideInt(v.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Uint() > math.MaxInt64 {
+				return nil, &RideConversionError{Value: value, Err: ErrRideOverflow}
+			}
+			return RideInt(v.Uint()), nil
+		case reflect.Ptr:
+			n, ok := v.Interface().(*big.Int)
+			if !ok || n == nil {
+				break
+			}
+			if n.Cmp(rideMinBigInt) < 0 || n.Cmp(rideMaxBigInt) > 0 {
+				return nil, &RideConversionError{Value: value, Err: ErrRideOverflow}
+			}
+			return RideBigInt{V: new(big.Int).Set(n)}, nil
+		}
+	case BoolTy:
+		if v.Kind() == reflect.Bool {
+			return RideBoolean(v.Bool()), nil
+		}
+	case StringTy:
+		if v.Kind() == reflect.String {
+			return RideString(v.String()), nil
+		}
+	case BytesTy, FixedBytesTy, HashTy, AddressTy:
+		if bts, ok := readBytes(v); ok {
+			return RideByteVector(bts), nil
+		}
+	case SliceTy, ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			break
+		}
+		if v.Len() > rideMaxListSize {
+			return nil, &RideConversionError{Value: value, Err: ErrRideSizeLimit}
+		}
+		list := make(RideList, v.Len())
+		for i := range list {
+			elem, err := toRideType(*t.Elem, v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case TupleTy:
+		if v.Kind() != reflect.Struct || v.NumField() != len(t.TupleElems) {
+			break
+		}
+		if v.NumField() < rideMinTupleSize || v.NumField() > rideMaxTupleSize {
+			return nil, &RideConversionError{Value: value, Err: ErrRideSizeLimit}
+		}
+		tuple := make(RideTuple, v.NumField())
+		for i := range tuple {
+			elem, err := toRideType(*t.TupleElems[i], v.Field(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			tuple[i] = elem
+		}
+		return tuple, nil
+	}
+	return nil, &RideConversionError{Value: value, Err: ErrRideUnsupportedType}
+}
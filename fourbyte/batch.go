@@ -0,0 +1,68 @@
+package fourbyte
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BatchCall is the call data of the batch with the optional recipient contract,
+// the contract ABIs are used for the call data decoding if the recipient is set.
+type BatchCall struct {
+	To   *Address
+	Data []byte
+}
+
+// BatchResult is the outcome of the batch call data decoding.
+type BatchResult struct {
+	Decoded *DecodedCallData
+	Err     error
+}
+
+// ParseCallDataBatch decodes the call data concurrently by the given number of workers,
+// see ParseCallDataNew and ParseContractCallDataNew. Results are in the order of calls.
+func (db *Database) ParseCallDataBatch(calls []BatchCall, workers int) []BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(calls) {
+		workers = len(calls)
+	}
+	var (
+		results = make([]BatchResult, len(calls))
+		indexes = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// each worker writes only its own results, so there's no need in locking
+				results[i] = db.ParseBatchCall(calls[i])
+			}
+		}()
+	}
+	for i := range calls {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// ParseBatchCall decodes the single call of the batch, see ParseCallDataBatch.
+// The decoder panic, e.g. caused by the malformed ABI, is recovered and reported as the error of the call,
+// so the single bad record doesn't crash the whole batch.
+func (db *Database) ParseBatchCall(call BatchCall) (result BatchResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = BatchResult{Err: fmt.Errorf("decoder panic: %v", r)}
+		}
+	}()
+	if call.To != nil {
+		result.Decoded, result.Err = db.ParseContractCallDataNew(*call.To, call.Data)
+	} else {
+		result.Decoded, result.Err = db.ParseCallDataNew(call.Data)
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestParseCallDataBatch(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	transfer := erc20Methods[erc20TransferSignature.Selector()]
+	recipient := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+
+	calls := make([]BatchCall, 100)
+	for i := range calls {
+		if i%10 == 3 {
+			calls[i].Data = []byte{1, 2, 3, 4}
+			continue
+		}
+		calls[i].Data, err = transfer.EncodeCall(recipient, big.NewInt(int64(i)))
+		require.NoError(t, err)
+	}
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	calls[5].To = &contract
+
+	results := db.ParseCallDataBatch(calls, 8)
+	require.Len(t, results, len(calls))
+	for i, result := range results {
+		if i%10 == 3 {
+			require.Error(t, result.Err, i)
+			continue
+		}
+		require.NoError(t, result.Err, i)
+		require.Equal(t, int64(i), result.Decoded.Inputs[1].DecodedValue().(*big.Int).Int64())
+	}
+	require.Empty(t, db.ParseCallDataBatch(nil, 8))
+
+	// the panic of the malformed ABI becomes the error of the call
+	broken := NewMethod("broken", Callable, Arguments{{Type: Type{T: ArrayTy, Size: 1}}}, nil)
+	db.AddContractABI(contract, &ABI{Methods: map[Selector]Method{broken.Sig.Selector(): broken}})
+	selector := broken.Sig.Selector()
+	calls[5].Data = append(selector[:], make([]byte, 32)...)
+	results = db.ParseCallDataBatch(calls, 8)
+	require.Error(t, results[5].Err)
+	require.Contains(t, results[5].Err.Error(), "panic")
+	require.NoError(t, results[6].Err)
+}
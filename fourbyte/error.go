@@ -0,0 +1,40 @@
+package fourbyte
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a custom solidity error which can be used in the revert statement.
+// The revert data of the custom error is encoded like a function call.
+type Error struct {
+	RawName string // RawName is the raw error name parsed from ABI
+	Inputs  Arguments
+
+	str string
+	// Sig contains the string signature according to the ABI spec.
+	// e.g.	 error foo(uint32 a, int b) = "foo(uint32,int256)"
+	Sig Signature
+}
+
+// NewError creates a new Error.
+// An error should always be created using NewError.
+// It also precomputes the sig representation and the string representation
+// of the error.
+func NewError(rawName string, inputs Arguments) Error {
+	names := make([]string, len(inputs))
+	for i, input := range inputs {
+		names[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
+	}
+
+	return Error{
+		RawName: rawName,
+		Inputs:  inputs,
+		str:     fmt.Sprintf("error %v(%v)", rawName, strings.Join(names, ", ")),
+		Sig:     NewSignature(rawName, inputs),
+	}
+}
+
+func (e *Error) String() string {
+	return e.str
+}
@@ -0,0 +1,54 @@
+package fourbyte
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Event is an event potentially triggered by the EVM's LOG mechanism. The Event
+// holds type information (inputs) about the yielded output. Anonymous events
+// don't get the signature canonical representation as the first LOG topic.
+type Event struct {
+	RawName   string // RawName is the raw event name parsed from ABI
+	Anonymous bool
+	Inputs    Arguments
+
+	str string
+	// Sig contains the string signature according to the ABI spec.
+	// e.g.	 event foo(uint32 a, int b) = "foo(uint32,int256)"
+	// Please note that "int" is substitute for its canonical representation "int256"
+	Sig Signature
+	// ID returns the canonical representation of the event's signature used by the
+	// abi definition to identify event names and types.
+	ID Hash
+}
+
+// NewEvent creates a new Event.
+// An event should always be created using NewEvent.
+// It also precomputes the sig representation and the string representation
+// of the event.
+func NewEvent(rawName string, anonymous bool, inputs Arguments) Event {
+	names := make([]string, len(inputs))
+	for i, input := range inputs {
+		names[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
+		if input.Indexed {
+			names[i] = fmt.Sprintf("%v indexed %v", input.Type, input.Name)
+		}
+	}
+
+	sig := NewSignature(rawName, inputs)
+	str := fmt.Sprintf("event %v(%v)", rawName, strings.Join(names, ", "))
+
+	return Event{
+		RawName:   rawName,
+		Anonymous: anonymous,
+		Inputs:    inputs,
+		str:       str,
+		Sig:       sig,
+		ID:        sig.Hash(),
+	}
+}
+
+func (e *Event) String() string {
+	return e.str
+}
@@ -0,0 +1,112 @@
+//go:build ignore
+// +build ignore
+
+// This program generates 4byte_data.go from the 4byte.json dump of the 4byte.directory.
+// It can be invoked by running go generate in the fourbyte directory.
+//
+// Each selector in the dump maps either to a single signature or to the list of
+// colliding signatures, the order of the colliding signatures is preserved.
+//
+// Several comma separated inputs are merged in the given order: 4byte.json is the dump of
+// the public 4byte.directory database (as shipped with go-ethereum), it holds a single signature
+// per selector, 4byte_extra.json adds the colliding signatures and the selectors missing from the dump.
+// The signatures are stored gzip compressed in the generated file.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const selectorLen = 4
+
+func main() {
+	inputs := flag.String("input", "4byte.json,4byte_extra.json",
+		"comma separated paths to the 4byte.json dumps (hex selector → signatures)",
+	)
+	output := flag.String("output", "4byte_data.go", "path to the generated go file")
+	flag.Parse()
+
+	normalized := make(map[string][]string)
+	for _, input := range strings.Split(*inputs, ",") {
+		if err := load(input, normalized); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// json.Marshal sorts map keys, so the output is deterministic
+	canonical, err := json.Marshal(normalized)
+	if err != nil {
+		log.Fatalf("failed to marshal signatures: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		log.Fatalf("failed to create gzip writer: %v", err)
+	}
+	if _, err := gz.Write(canonical); err != nil {
+		log.Fatalf("failed to compress signatures: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		log.Fatalf("failed to compress signatures: %v", err)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by go run gen.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package fourbyte\n\n")
+	fmt.Fprintf(&src, "// embedded4byteJsonGz holds %d gzip compressed selectors in the 4byte.json format.\n", len(normalized))
+	fmt.Fprintf(&src, "const embedded4byteJsonGz = %s\n", strconv.QuoteToASCII(compressed.String()))
+	if err := ioutil.WriteFile(*output, src.Bytes(), 0644); err != nil {
+		log.Fatalf("failed to write %q: %v", *output, err)
+	}
+}
+
+// load merges the signatures of the dump into normalized, duplicate signatures are skipped.
+func load(input string, normalized map[string][]string) error {
+	blob, err := ioutil.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %v", input, err)
+	}
+	// each selector holds either a single signature or the list of colliding signatures
+	var signatures map[string]json.RawMessage
+	if err := json.Unmarshal(blob, &signatures); err != nil {
+		return fmt.Errorf("failed to parse %q: %v", input, err)
+	}
+	for hexSelector, raw := range signatures {
+		var candidates []string
+		if err := json.Unmarshal(raw, &candidates); err != nil {
+			var signature string
+			if err := json.Unmarshal(raw, &signature); err != nil {
+				return fmt.Errorf("invalid signatures for selector %q in %q: %s", hexSelector, input, raw)
+			}
+			candidates = []string{signature}
+		}
+		hexSelector = strings.ToLower(strings.TrimPrefix(hexSelector, "0x"))
+		if bts, err := hex.DecodeString(hexSelector); err != nil || len(bts) != selectorLen {
+			return fmt.Errorf("invalid selector %q for signatures %q in %q", hexSelector, candidates, input)
+		}
+		for _, candidate := range candidates {
+			if !contains(normalized[hexSelector], candidate) {
+				normalized[hexSelector] = append(normalized[hexSelector], candidate)
+			}
+		}
+	}
+	return nil
+}
+
+func contains(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package fourbyte
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// DecodedLog is the event log decoded according to the ABI event.
+// Inputs are in the order of the event arguments, both indexed and non-indexed.
+type DecodedLog struct {
+	Signature string       `json:"signature"`
+	Name      string       `json:"name"`
+	Inputs    []ArgDecoded `json:"inputs"`
+}
+
+// String implements stringer interface for DecodedLog
+func (dl DecodedLog) String() string {
+	args := make([]string, len(dl.Inputs))
+	for i, arg := range dl.Inputs {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", dl.Name, strings.Join(args, ","))
+}
+
+// DecodeLog decodes the event log using the embedded ERC20 events, see Database.DecodeLog.
+func DecodeLog(topics [][32]byte, data []byte) (*DecodedLog, error) {
+	db, err := NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return db.DecodeLog(topics, data)
+}
+
+// DecodeLog decodes the event log using the events of the global ABIs.
+// The first topic is the event ID, so anonymous events can't be decoded.
+func (db *Database) DecodeLog(topics [][32]byte, data []byte) (*DecodedLog, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("log without topics can't be decoded")
+	}
+	event, ok := eventByID(db.snapshot().abis, topics[0])
+	if !ok {
+		return nil, errors.Errorf("no event with id: %#x", topics[0][:])
+	}
+	return decodeLog(&event, topics, data)
+}
+
+// DecodeContractLog decodes the event log using the events of the contract and global ABIs.
+// Events of the contract ABIs take precedence over the events of the global ABIs.
+func (db *Database) DecodeContractLog(contract Address, topics [][32]byte, data []byte) (*DecodedLog, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("log without topics can't be decoded")
+	}
+	state := db.snapshot()
+	for _, abis := range [][]*ABI{state.contractABIs[contract], state.abis} {
+		if event, ok := eventByID(abis, topics[0]); ok {
+			return decodeLog(&event, topics, data)
+		}
+	}
+	return nil, errors.Errorf("no event with id: %#x", topics[0][:])
+}
+
+// decodeLog decodes indexed values from the topics and the rest from the data.
+// Indexed values of dynamic types, arrays and tuples are reported as their hashes,
+// such arguments keep the declared type and are marked as hashed.
+func decodeLog(event *Event, topics [][32]byte, data []byte) (*DecodedLog, error) {
+	var (
+		nonIndexed   Arguments
+		indexedCount int
+	)
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexedCount++
+		} else {
+			nonIndexed = append(nonIndexed, arg)
+		}
+	}
+	if len(topics)-1 != indexedCount {
+		return nil, errors.Errorf("event %v has %d indexed arguments, got %d topics", event.Sig, indexedCount, len(topics)-1)
+	}
+	values, err := nonIndexed.UnpackValues(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unpack non-indexed arguments of log data")
+	}
+	encoded, err := nonIndexed.Pack(values...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack decoded non-indexed arguments")
+	}
+	if ranges := diffRanges(encoded, data, 0); len(ranges) != 0 {
+		return nil, &StuffedDataError{Signature: event.Sig, Ranges: ranges}
+	}
+
+	decoded := DecodedLog{Signature: event.Sig.String(), Name: event.RawName}
+	topics = topics[1:]
+	for _, arg := range event.Inputs {
+		if !arg.Indexed {
+			decoded.Inputs = append(decoded.Inputs, &decodedArg{Soltype: arg, Value: values[0]})
+			values = values[1:]
+			continue
+		}
+		topic := topics[0]
+		topics = topics[1:]
+		if isHashedTopic(arg.Type) {
+			decoded.Inputs = append(decoded.Inputs, &decodedArg{Soltype: arg, Value: Hash(topic), Hashed: true})
+			continue
+		}
+		value, err := toGoType(0, arg.Type, topic[:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode indexed argument %q", arg.Name)
+		}
+		// the topic must be the canonical encoding of the value, e.g. addresses are zero padded
+		encoded, err := arg.Type.pack(reflect.ValueOf(value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to pack indexed argument %q", arg.Name)
+		}
+		if !bytes.Equal(encoded, topic[:]) {
+			return nil, errors.Errorf("topic of indexed argument %q is not canonically encoded", arg.Name)
+		}
+		decoded.Inputs = append(decoded.Inputs, &decodedArg{Soltype: arg, Value: value})
+	}
+	return &decoded, nil
+}
+
+// isHashedTopic reports whether the indexed value of the type is stored as its keccak256 hash.
+func isHashedTopic(t Type) bool {
+	switch t.T {
+	case StringTy, BytesTy, SliceTy, ArrayTy, TupleTy:
+		return true
+	default:
+		return false
+	}
+}
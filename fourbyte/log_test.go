@@ -0,0 +1,97 @@
+package fourbyte
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func addressTopic(a Address) [32]byte {
+	var topic [32]byte
+	copy(topic[32-AddressLength:], a[:])
+	return topic
+}
+
+func TestDecodeLogERC20(t *testing.T) {
+	from := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	to := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	data := packNum(big.NewInt(1000))
+
+	decoded, err := DecodeLog([][32]byte{erc20TransferEventSignature.Hash(), addressTopic(from), addressTopic(to)}, data)
+	require.NoError(t, err)
+	require.Equal(t, "Transfer", decoded.Name)
+	require.Equal(t, erc20TransferEventSignature.String(), decoded.Signature)
+	require.Len(t, decoded.Inputs, 3)
+	require.Equal(t, from, decoded.Inputs[0].DecodedValue())
+	require.Equal(t, to, decoded.Inputs[1].DecodedValue())
+	require.Equal(t, big.NewInt(1000), decoded.Inputs[2].DecodedValue())
+
+	decoded, err = DecodeLog([][32]byte{erc20ApprovalEventSignature.Hash(), addressTopic(from), addressTopic(to)}, data)
+	require.NoError(t, err)
+	require.Equal(t, "Approval", decoded.Name)
+
+	// wrong number of topics
+	_, err = DecodeLog([][32]byte{erc20TransferEventSignature.Hash(), addressTopic(from)}, data)
+	require.Error(t, err)
+	// dirty address padding
+	dirty := addressTopic(to)
+	dirty[0] = 1
+	_, err = DecodeLog([][32]byte{erc20TransferEventSignature.Hash(), addressTopic(from), dirty}, data)
+	require.Error(t, err)
+	// trailing data
+	_, err = DecodeLog([][32]byte{erc20TransferEventSignature.Hash(), addressTopic(from), addressTopic(to)}, append(data, 1))
+	var stuffedErr *StuffedDataError
+	require.True(t, errors.As(err, &stuffedErr))
+	require.Equal(t, []ByteRange{{Start: 32, End: 33}}, stuffedErr.Ranges)
+
+	_, err = DecodeLog(nil, data)
+	require.Error(t, err)
+	_, err = DecodeLog([][32]byte{{1}}, data)
+	require.Error(t, err)
+}
+
+func TestDecodeContractLogHashedTopics(t *testing.T) {
+	contractABI, err := JSON(strings.NewReader(`[{
+		"type": "event", "name": "Named", "inputs": [
+			{"name": "name", "type": "string", "indexed": true},
+			{"name": "id", "type": "uint64", "indexed": true},
+			{"name": "tags", "type": "string[]"}
+		]
+	}]`))
+	require.NoError(t, err)
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	db.AddContractABI(contract, contractABI)
+
+	event := contractABI.Events[Signature("Named(string,uint64,string[])").Hash()]
+	nameHash := Signature("name").Hash()
+	var idTopic [32]byte
+	idTopic[31] = 7
+	data, err := newTestArguments(t, "string[]").Pack([]string{"a", "b"})
+	require.NoError(t, err)
+	topics := [][32]byte{event.ID, nameHash, idTopic}
+
+	_, err = db.DecodeLog(topics, data)
+	require.Error(t, err)
+	decoded, err := db.DecodeContractLog(contract, topics, data)
+	require.NoError(t, err)
+	require.Equal(t, "Named", decoded.Name)
+	// the hashed argument keeps the declared type
+	require.Equal(t, "string", decoded.Inputs[0].(*decodedArg).Soltype.Type.String())
+	require.True(t, decoded.Inputs[0].(*decodedArg).Hashed)
+	require.Equal(t, byte(HashTy), decoded.Inputs[0].InternalType())
+	require.Equal(t, nameHash, decoded.Inputs[0].DecodedValue())
+	require.Equal(t, "string: hash "+nameHash.Hex(), decoded.Inputs[0].String())
+	blob, err := decoded.Inputs[0].MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"name","type":"string","hashed":true,"value":"0x`+nameHash.Hex()+`"}`, string(blob))
+	var unmarshaled decodedArg
+	require.NoError(t, unmarshaled.UnmarshalJSON(blob))
+	require.True(t, unmarshaled.Hashed)
+	require.Equal(t, nameHash, unmarshaled.DecodedValue())
+	require.Equal(t, uint64(7), decoded.Inputs[1].DecodedValue())
+	require.Equal(t, []string{"a", "b"}, decoded.Inputs[2].DecodedValue())
+}
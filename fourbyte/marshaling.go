@@ -0,0 +1,355 @@
+package fourbyte
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// jsonCallData is the JSON representation of DecodedCallData.
+type jsonCallData struct {
+	Signature string            `json:"signature"`
+	Selector  string            `json:"selector"`
+	Name      string            `json:"name"`
+	Inputs    []json.RawMessage `json:"inputs"`
+	Payments  []Payment         `json:"payments,omitempty"`
+	Verifier  bool              `json:"verifier,omitempty"`
+}
+
+// jsonArgument is the JSON representation of the decoded argument. Name, type and components
+// follow the Solidity JSON ABI, e.g. tuples have the "tuple" type with the components.
+type jsonArgument struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Components []jsonComponent `json:"components,omitempty"`
+	// Hashed is set for the indexed event arguments which value is the 0x-prefixed hex keccak256 hash.
+	Hashed bool            `json:"hashed,omitempty"`
+	Value  json.RawMessage `json:"value"`
+}
+
+type jsonComponent struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Components []jsonComponent `json:"components,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface. Integers are encoded as decimal strings,
+// bytes as 0x-prefixed hex strings, addresses are checksummed, arrays and tuples are nested JSON arrays.
+func (cd DecodedCallData) MarshalJSON() ([]byte, error) {
+	selector := NewSelector(Signature(cd.Signature))
+	data := jsonCallData{
+		Signature: cd.Signature,
+		Selector:  "0x" + selector.Hex(),
+		Name:      cd.Name,
+		Inputs:    make([]json.RawMessage, len(cd.Inputs)),
+		Payments:  cd.Payments,
+		Verifier:  cd.Verifier,
+	}
+	for i, input := range cd.Inputs {
+		blob, err := input.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal input %d: %v", i, err)
+		}
+		data.Inputs[i] = blob
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+// The decoded values have the same Go types as the values decoded from the call data.
+func (cd *DecodedCallData) UnmarshalJSON(blob []byte) error {
+	var data jsonCallData
+	if err := json.Unmarshal(blob, &data); err != nil {
+		return err
+	}
+	selector := NewSelector(Signature(data.Signature))
+	if data.Selector != "0x"+selector.Hex() {
+		return fmt.Errorf("selector %q doesn't match signature %q", data.Selector, data.Signature)
+	}
+	inputs := make([]ArgDecoded, len(data.Inputs))
+	for i, input := range data.Inputs {
+		arg := new(decodedArg)
+		if err := arg.UnmarshalJSON(input); err != nil {
+			return fmt.Errorf("failed to unmarshal input %d: %v", i, err)
+		}
+		inputs[i] = arg
+	}
+	*cd = DecodedCallData{
+		Signature: data.Signature,
+		Name:      data.Name,
+		Inputs:    inputs,
+		Payments:  data.Payments,
+		Verifier:  data.Verifier,
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface, see DecodedCallData.MarshalJSON.
+func (da *decodedArg) MarshalJSON() ([]byte, error) {
+	valueType := da.Soltype.Type
+	if da.Hashed {
+		valueType = hashType
+	}
+	value, err := marshalValue(valueType, reflect.ValueOf(da.Value))
+	if err != nil {
+		return nil, err
+	}
+	blob, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	typ, components := jsonType(da.Soltype.Type)
+	return json.Marshal(jsonArgument{
+		Name:       da.Soltype.Name,
+		Type:       typ,
+		Components: components,
+		Hashed:     da.Hashed,
+		Value:      blob,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (da *decodedArg) UnmarshalJSON(blob []byte) error {
+	var arg jsonArgument
+	if err := json.Unmarshal(blob, &arg); err != nil {
+		return err
+	}
+	typ, err := newTypeWithComponents(arg.Type, "", toArgumentMarshaling(arg.Components))
+	if err != nil {
+		return err
+	}
+	soltype := Argument{Name: arg.Name, Type: typ}
+	if arg.Hashed {
+		value, err := unmarshalValue(hashType, arg.Value)
+		if err != nil {
+			return fmt.Errorf("invalid %v hash value: %v", typ, err)
+		}
+		soltype.Indexed = true
+		*da = decodedArg{Soltype: soltype, Value: value.Interface(), Hashed: true}
+		return nil
+	}
+	value, err := unmarshalValue(typ, arg.Value)
+	if err != nil {
+		return fmt.Errorf("invalid %v value: %v", typ, err)
+	}
+	// packing validates the integers ranges
+	if _, err := (Arguments{soltype}).Pack(value.Interface()); err != nil {
+		return err
+	}
+	*da = decodedArg{Soltype: soltype, Value: value.Interface()}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface, see DecodedCallData.MarshalJSON.
+// Note the names of the legacy tuple components are not preserved.
+func (arg *ethDecodedArgument) MarshalJSON() ([]byte, error) {
+	typ, err := NewType(arg.Soltype.Type.String())
+	if err != nil {
+		return nil, err
+	}
+	native := decodedArg{Soltype: Argument{Name: arg.Soltype.Name, Type: typ}, Value: arg.Value}
+	return native.MarshalJSON()
+}
+
+// hashType is the type of the hashed values, see decodedArg.Hashed.
+var hashType = Type{T: HashTy, Size: 32, stringKind: "bytes32"}
+
+// jsonType returns the Solidity JSON ABI type and components of the type.
+func jsonType(t Type) (string, []jsonComponent) {
+	switch t.T {
+	case SliceTy:
+		typ, components := jsonType(*t.Elem)
+		return typ + "[]", components
+	case ArrayTy:
+		typ, components := jsonType(*t.Elem)
+		return fmt.Sprintf("%s[%d]", typ, t.Size), components
+	case TupleTy:
+		components := make([]jsonComponent, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			if i < len(t.TupleRawNames) {
+				components[i].Name = t.TupleRawNames[i]
+			}
+			components[i].Type, components[i].Components = jsonType(*elem)
+		}
+		return "tuple", components
+	default:
+		return t.String(), nil
+	}
+}
+
+func toArgumentMarshaling(components []jsonComponent) []ArgumentMarshaling {
+	if len(components) == 0 {
+		return nil
+	}
+	result := make([]ArgumentMarshaling, len(components))
+	for i, c := range components {
+		result[i] = ArgumentMarshaling{Name: c.Name, Type: c.Type, Components: toArgumentMarshaling(c.Components)}
+	}
+	return result
+}
+
+// marshalValue converts the decoded value of the type to the JSON compatible value.
+func marshalValue(t Type, v reflect.Value) (interface{}, error) {
+	v = indirect(v)
+	switch t.T {
+	case IntTy, UintTy:
+		n, err := readNum(t, v)
+		if err != nil {
+			return nil, err
+		}
+		return n.String(), nil
+	case BoolTy:
+		if v.Kind() != reflect.Bool {
+			return nil, typeErr(t, v)
+		}
+		return v.Bool(), nil
+	case StringTy:
+		if v.Kind() != reflect.String {
+			return nil, typeErr(t, v)
+		}
+		return v.String(), nil
+	case BytesTy, FixedBytesTy, HashTy, AddressTy:
+		bts, ok := readBytes(v)
+		if !ok {
+			return nil, typeErr(t, v)
+		}
+		if t.T == AddressTy {
+			return BytesToAddress(bts).Hex(), nil
+		}
+		return "0x" + hex.EncodeToString(bts), nil
+	case SliceTy, ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, typeErr(t, v)
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			value, err := marshalValue(*t.Elem, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case TupleTy:
+		if v.Kind() != reflect.Struct || v.NumField() != len(t.TupleElems) {
+			return nil, typeErr(t, v)
+		}
+		values := make([]interface{}, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			value, err := marshalValue(*elem, v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("abi: could not marshal value, unknown type: %v", t.T)
+	}
+}
+
+// unmarshalValue converts the JSON value produced by marshalValue to the value of the Go type of t.
+func unmarshalValue(t Type, raw json.RawMessage) (reflect.Value, error) {
+	goType := t.GetType()
+	switch t.T {
+	case IntTy, UintTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("invalid decimal integer %q", s)
+		}
+		if goType == reflect.TypeOf(n) {
+			return reflect.ValueOf(n), nil
+		}
+		v := reflect.New(goType).Elem()
+		switch {
+		case t.T == IntTy && n.IsInt64() && !v.OverflowInt(n.Int64()):
+			v.SetInt(n.Int64())
+		case t.T == UintTy && n.IsUint64() && !v.OverflowUint(n.Uint64()):
+			v.SetUint(n.Uint64())
+		default:
+			return reflect.Value{}, fmt.Errorf("integer %s overflows %v", s, t)
+		}
+		return v, nil
+	case BoolTy:
+		var b bool
+		err := json.Unmarshal(raw, &b)
+		return reflect.ValueOf(b), err
+	case StringTy:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return reflect.ValueOf(s), err
+	case AddressTy:
+		var a Address
+		err := json.Unmarshal(raw, &a)
+		return reflect.ValueOf(a), err
+	case BytesTy, FixedBytesTy, HashTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		if !strings.HasPrefix(s, "0x") {
+			return reflect.Value{}, fmt.Errorf("hex string %q without 0x prefix", s)
+		}
+		bts, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if t.T == BytesTy {
+			return reflect.ValueOf(bts), nil
+		}
+		if len(bts) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", t.Size, len(bts))
+		}
+		v := reflect.New(goType).Elem()
+		reflect.Copy(v, reflect.ValueOf(bts))
+		return v, nil
+	case SliceTy, ArrayTy:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return reflect.Value{}, err
+		}
+		var v reflect.Value
+		if t.T == SliceTy {
+			v = reflect.MakeSlice(goType, len(elems), len(elems))
+		} else {
+			if len(elems) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d array elements, got %d", t.Size, len(elems))
+			}
+			v = reflect.New(goType).Elem()
+		}
+		for i, elem := range elems {
+			value, err := unmarshalValue(*t.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(value)
+		}
+		return v, nil
+	case TupleTy:
+		var fields []json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return reflect.Value{}, err
+		}
+		if len(fields) != len(t.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("expected %d tuple components, got %d", len(t.TupleElems), len(fields))
+		}
+		v := reflect.New(goType).Elem()
+		for i, field := range fields {
+			value, err := unmarshalValue(*t.TupleElems[i], field)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Field(i).Set(value)
+		}
+		return v, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("abi: could not unmarshal value, unknown type: %v", t.T)
+	}
+}
@@ -0,0 +1,103 @@
+package fourbyte
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDecodedCallDataJSON(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	// from https://etherscan.io/tx/0x363f979b58c82614db71229c2a57ed760e7bc454ee29c2f8fd1df99028667ea5
+	data, err := hex.DecodeString("a9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c000000000000000000000000000000000000000000002c5b68601cc92ad60000")
+	require.NoError(t, err)
+
+	expected := `{"signature":"transfer(address,uint256)","selector":"0xa9059cbb","name":"transfer","inputs":[` +
+		`{"name":"_to","type":"address","value":"0x9a1989946ae4249AAC19ac7a038d24Aab03c3D8c"},` +
+		`{"name":"_value","type":"uint256","value":"209470300000000000000000"}]}`
+	decoded, err := db.ParseCallDataNew(data)
+	require.NoError(t, err)
+	blob, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(blob))
+
+	// legacy decoder doesn't have arguments names
+	legacy, err := db.ParseCallData(data)
+	require.NoError(t, err)
+	blob, err = json.Marshal(legacy)
+	require.NoError(t, err)
+	require.Equal(t, strings.NewReplacer(`"_to"`, `""`, `"_value"`, `""`).Replace(expected), string(blob))
+
+	var unmarshaled DecodedCallData
+	require.NoError(t, json.Unmarshal(blob, &unmarshaled))
+	require.Equal(t, decoded.Signature, unmarshaled.Signature)
+	require.Equal(t, decoded.Inputs[0].DecodedValue(), unmarshaled.Inputs[0].DecodedValue())
+	require.Equal(t, decoded.Inputs[1].DecodedValue(), unmarshaled.Inputs[1].DecodedValue())
+}
+
+func TestDecodedCallDataJSONRoundTrip(t *testing.T) {
+	method, err := NewMethodFromSignature("f(int8,uint64,bool,string,bytes,bytes4,address[2],(uint8,string)[],(bytes32,int64)[])")
+	require.NoError(t, err)
+	method.Payments = true
+	tuples := newTestArguments(t, "(uint8,string)[]")
+	tuplesData, err := tuples.Pack([]struct {
+		A uint8
+		B string
+	}{{1, "a"}, {2, "b"}})
+	require.NoError(t, err)
+	tuplesValue, err := tuples.UnpackValues(tuplesData)
+	require.NoError(t, err)
+	payment := newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, [32]byte{1}, int64(7))
+	data, err := method.EncodeCall(
+		int8(-8), uint64(1<<63), true, "str", []byte{1, 2}, [4]byte{1, 2, 3, 4},
+		[2]Address{{1}, {2}}, tuplesValue[0], []interface{}{payment},
+	)
+	require.NoError(t, err)
+	decoded, err := parseArgData(&method, data[selectorLen:])
+	require.NoError(t, err)
+
+	blob, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	require.Contains(t, string(blob), `{"name":"","type":"int8","value":"-8"}`)
+	require.Contains(t, string(blob), `"type":"tuple[]","components":[{"name":"","type":"uint8"},{"name":"","type":"string"}],"value":[["1","a"],["2","b"]]`)
+	require.Contains(t, string(blob), `"payments":[{"assetId":"0x01`)
+
+	var unmarshaled DecodedCallData
+	require.NoError(t, json.Unmarshal(blob, &unmarshaled))
+	require.Len(t, unmarshaled.Inputs, len(decoded.Inputs))
+	for i := range decoded.Inputs {
+		require.Equal(t, decoded.Inputs[i].DecodedValue(), unmarshaled.Inputs[i].DecodedValue(), i)
+	}
+	require.Equal(t, decoded.Payments, unmarshaled.Payments)
+	reencoded, err := json.Marshal(unmarshaled)
+	require.NoError(t, err)
+	require.JSONEq(t, string(blob), string(reencoded))
+}
+
+func TestDecodedCallDataJSONInvalid(t *testing.T) {
+	valid := `{"signature":"f(uint8)","selector":"0x%s","name":"f","inputs":[{"name":"","type":"uint8","value":"1"}]}`
+	selector := Signature("f(uint8)").Selector()
+	var decoded DecodedCallData
+	require.NoError(t, json.Unmarshal([]byte(strings.Replace(valid, "%s", selector.Hex(), 1)), &decoded))
+	require.Equal(t, uint8(1), decoded.Inputs[0].DecodedValue())
+
+	for _, blob := range []string{
+		strings.Replace(valid, "%s", "a9059cbb", 1),
+		strings.Replace(strings.Replace(valid, "%s", selector.Hex(), 1), `"1"`, `"256"`, 1),
+		strings.Replace(strings.Replace(valid, "%s", selector.Hex(), 1), `"1"`, `1`, 1),
+		strings.Replace(strings.Replace(valid, "%s", selector.Hex(), 1), `uint8`, `uint7`, 1),
+	} {
+		require.Error(t, json.Unmarshal([]byte(blob), &decoded), blob)
+	}
+
+	var arg decodedArg
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"a","type":"int256","value":"-1"}`), &arg))
+	require.Equal(t, big.NewInt(-1), arg.DecodedValue())
+	require.Error(t, json.Unmarshal([]byte(`{"name":"a","type":"address","value":"0x9A1989946ae4249aac19ac7a038d24aab03c3d8c"}`), &arg))
+	require.Error(t, json.Unmarshal([]byte(`{"name":"a","type":"bytes4","value":"0x010203"}`), &arg))
+	require.Error(t, json.Unmarshal([]byte(`{"name":"a","type":"uint256[2]","value":["1"]}`), &arg))
+}
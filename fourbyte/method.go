@@ -2,14 +2,23 @@ package fourbyte
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
 type FunctionType byte
 
+// VerifierName is the reserved name of the Waves dApp verifier function in the method signature.
+// Ride verifiers may have any name and don't have arguments, so verifier invocations are encoded
+// like the call of the "verify()" function regardless of the verifier name.
+const VerifierName = "verify"
+
 const (
 	Callable FunctionType = iota
 	Verifier
+	Constructor
+	Fallback
+	Receive
 )
 
 type Method struct {
@@ -17,6 +26,11 @@ type Method struct {
 
 	RawName string // RawName is the raw method name parsed from ABI
 	Inputs  Arguments
+	Outputs Arguments
+
+	// Payments reports whether the trailing "(bytes32,int64)[]" input is the payments argument
+	// of the Waves dApp method, see PaymentsArgumentName. It's set for the methods of Ride dApps.
+	Payments bool
 
 	str string
 	// Sig returns the methods string signature according to the ABI spec.
@@ -29,14 +43,16 @@ type Method struct {
 // A method should always be created using NewMethod.
 // It also precomputes the sig representation and the string representation
 // of the method.
-// TODO(nickeskov): remove outputs
 func NewMethod(rawName string, funType FunctionType, inputs, outputs Arguments) Method {
 	var (
 		inputNames  = make([]string, len(inputs))
 		outputNames = make([]string, len(outputs))
 	)
 	for i, input := range inputs {
-		inputNames[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
+		inputNames[i] = input.Type.String()
+		if len(input.Name) > 0 {
+			inputNames[i] += fmt.Sprintf(" %v", input.Name)
+		}
 	}
 	for i, output := range outputs {
 		outputNames[i] = output.Type.String()
@@ -44,18 +60,28 @@ func NewMethod(rawName string, funType FunctionType, inputs, outputs Arguments)
 			outputNames[i] += fmt.Sprintf(" %v", output.Name)
 		}
 	}
-	// calculate the signature and method id. Note only function
-	// has meaningful signature and id.
+	// calculate the signature and method id. Note only function and verifier
+	// have meaningful signature and id.
 	var (
 		sig Signature
 	)
-	if funType == Callable {
+	switch funType {
+	case Callable:
 		sig = NewSignature(rawName, inputs)
+	case Verifier:
+		sig = NewSignature(VerifierName, inputs)
 	}
 
 	identity := fmt.Sprintf("function %v", rawName)
-	if funType == Verifier {
-		identity = "verifier"
+	switch funType {
+	case Verifier:
+		identity = fmt.Sprintf("verifier %v", rawName)
+	case Constructor:
+		identity = "constructor"
+	case Fallback:
+		identity = "fallback"
+	case Receive:
+		identity = "receive"
 	}
 
 	str := fmt.Sprintf("%v(%v) returns(%v)", identity, strings.Join(inputNames, ", "), strings.Join(outputNames, ", "))
@@ -64,7 +90,8 @@ func NewMethod(rawName string, funType FunctionType, inputs, outputs Arguments)
 		RawName: rawName,
 		Type:    funType,
 
-		Inputs: inputs,
+		Inputs:  inputs,
+		Outputs: outputs,
 
 		str: str,
 
@@ -72,10 +99,77 @@ func NewMethod(rawName string, funType FunctionType, inputs, outputs Arguments)
 	}
 }
 
+// signatureRegexp is used to split the function signature into the name and the arguments part.
+var signatureRegexp = regexp.MustCompile(`^([A-Za-z_$][A-Za-z0-9_$]*)\((.*)\)$`)
+
+// NewMethodFromSignature creates a new callable Method from the function signature,
+// e.g. "transfer(address,uint256)". The arguments of such method don't have names.
+// Note that the method signature is converted to the canonical representation,
+// e.g. "foo(uint)" is converted to "foo(uint256)".
+func NewMethodFromSignature(signature string) (Method, error) {
+	groups := signatureRegexp.FindStringSubmatch(signature)
+	if len(groups) != 3 {
+		return Method{}, fmt.Errorf("invalid signature %q", signature)
+	}
+	name, args := groups[1], groups[2]
+
+	var inputs Arguments
+	if len(args) > 0 {
+		components, err := splitTupleComponents(args)
+		if err != nil {
+			return Method{}, fmt.Errorf("invalid signature %q: %v", signature, err)
+		}
+		inputs = make(Arguments, len(components))
+		for i, component := range components {
+			typ, err := NewType(component)
+			if err != nil {
+				return Method{}, fmt.Errorf("invalid signature %q: %v", signature, err)
+			}
+			inputs[i] = Argument{Type: typ}
+		}
+	}
+	return NewMethod(name, Callable, inputs, nil), nil
+}
+
+// EncodeCall packs the call data for the method: the 4-byte selector followed by the ABI-encoded values.
+func (m *Method) EncodeCall(values ...interface{}) ([]byte, error) {
+	if m.Sig == "" {
+		return nil, fmt.Errorf("method %q doesn't have a selector", m.RawName)
+	}
+	packed, err := m.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	selector := m.Sig.Selector()
+	return append(selector[:], packed...), nil
+}
+
+// DecodeReturn decodes the data returned by the method call, e.g. the eth_call result.
+// The data must be the canonical encoding of the outputs, see StuffedDataError.
+func (m *Method) DecodeReturn(data []byte) ([]ArgDecoded, error) {
+	values, err := m.Outputs.UnpackValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack outputs of method %q: %v", m.RawName, err)
+	}
+	encoded, err := m.Outputs.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack decoded outputs of method %q: %v", m.RawName, err)
+	}
+	if ranges := diffRanges(encoded, data, 0); len(ranges) != 0 {
+		return nil, &StuffedDataError{Signature: m.Sig, Ranges: ranges}
+	}
+	decoded := make([]ArgDecoded, len(m.Outputs))
+	for i := range m.Outputs {
+		decoded[i] = &decodedArg{Soltype: m.Outputs[i], Value: values[i]}
+	}
+	return decoded, nil
+}
+
 func (m *Method) String() string {
 	return m.str
 }
 
+// IsERC20 reports whether the method is the ERC20 transfer or transferFrom, other ERC20 methods are not matched.
 func (m *Method) IsERC20() bool {
 	_, isERC20 := erc20Methods[m.Sig.Selector()]
 	return isERC20
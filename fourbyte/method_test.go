@@ -0,0 +1,64 @@
+package fourbyte
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestMethodString(t *testing.T) {
+	balanceOf := erc20ABIMethods[erc20BalanceOfSignature.Selector()]
+	require.Equal(t, "function balanceOf(address _owner) returns(uint256 balance)", balanceOf.String())
+	decimals := erc20ABIMethods[erc20DecimalsSignature.Selector()]
+	require.Equal(t, "function decimals() returns(uint8)", decimals.String())
+
+	method, err := NewMethodFromSignature("transfer(address,uint256)")
+	require.NoError(t, err)
+	require.Equal(t, "function transfer(address, uint256) returns()", method.String())
+}
+
+func TestMethodDecodeReturn(t *testing.T) {
+	balanceOf := erc20ABIMethods[erc20BalanceOfSignature.Selector()]
+	data := packNum(big.NewInt(42))
+	decoded, err := balanceOf.DecodeReturn(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.Equal(t, big.NewInt(42), decoded[0].DecodedValue())
+
+	decimals := erc20ABIMethods[erc20DecimalsSignature.Selector()]
+	decoded, err = decimals.DecodeReturn(packNum(big.NewInt(18)))
+	require.NoError(t, err)
+	require.Equal(t, uint8(18), decoded[0].DecodedValue())
+
+	symbol := erc20ABIMethods[erc20SymbolSignature.Selector()]
+	data, err = symbol.Outputs.Pack("USDT")
+	require.NoError(t, err)
+	decoded, err = symbol.DecodeReturn(data)
+	require.NoError(t, err)
+	require.Equal(t, "USDT", decoded[0].DecodedValue())
+
+	// decimals don't fit into uint8
+	_, err = decimals.DecodeReturn(packNum(big.NewInt(256)))
+	var stuffedErr *StuffedDataError
+	require.True(t, errors.As(err, &stuffedErr))
+	require.Equal(t, []ByteRange{{Start: 0, End: 32}}, stuffedErr.Ranges)
+
+	_, err = balanceOf.DecodeReturn(nil)
+	require.Error(t, err)
+}
+
+func TestMethodIsERC20(t *testing.T) {
+	erc20ABI := ERC20ABI()
+	for _, sig := range []Signature{erc20TransferSignature, erc20TransferFromSignature} {
+		method := erc20ABI.Methods[sig.Selector()]
+		require.True(t, method.IsERC20(), sig)
+	}
+	// view calls and approvals are not transfers
+	for _, sig := range []Signature{erc20ApproveSignature, erc20BalanceOfSignature, erc20DecimalsSignature} {
+		method, ok := erc20ABI.Methods[sig.Selector()]
+		require.True(t, ok, sig)
+		require.False(t, method.IsERC20(), sig)
+		require.NotEmpty(t, method.Outputs, sig)
+	}
+}
@@ -0,0 +1,225 @@
+package fourbyte
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// pack packs the given reflect value according to the abi specification in t.
+func (t Type) pack(v reflect.Value) ([]byte, error) {
+	// dereference pointers and interfaces first
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("abi: cannot use nil as type %v", t)
+	}
+
+	switch t.T {
+	case SliceTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, typeErr(t, v)
+		}
+		packed, err := packTuple(repeatType(t.Elem, v.Len()), sequenceValues(v))
+		if err != nil {
+			return nil, err
+		}
+		// T[] is encoded as the T[k] with the k (length) prefix
+		return append(packNum(big.NewInt(int64(v.Len()))), packed...), nil
+	case ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, typeErr(t, v)
+		}
+		if v.Len() != t.Size {
+			return nil, fmt.Errorf("abi: cannot use array of length %d as type %v", v.Len(), t)
+		}
+		return packTuple(repeatType(t.Elem, v.Len()), sequenceValues(v))
+	case TupleTy:
+		// (T1,...,Tk) values are packed from the struct fields in the order of definition
+		if v.Kind() != reflect.Struct {
+			return nil, typeErr(t, v)
+		}
+		if v.NumField() != len(t.TupleElems) {
+			return nil, fmt.Errorf("abi: cannot use struct with %d fields as type %v", v.NumField(), t)
+		}
+		values := make([]reflect.Value, v.NumField())
+		for i := range values {
+			values[i] = v.Field(i)
+		}
+		return packTuple(t.TupleElems, values)
+	default:
+		return packElement(t, v)
+	}
+}
+
+// packTuple packs the values according to the abi specification of the tuple:
+//
+//	enc(X) = head(X(1)) ... head(X(k)) tail(X(1)) ... tail(X(k))
+//
+// where head(X(i)) = enc(X(i)) and tail(X(i)) = "" for the static type Ti, otherwise
+//
+//	head(X(i)) = enc(len(head(X(1)) ... head(X(k)) tail(X(1)) ... tail(X(i-1))))
+//	tail(X(i)) = enc(X(i))
+//
+// Arguments lists, T[k] and T[] (without the length prefix) are encoded in the same way.
+func packTuple(types []*Type, values []reflect.Value) ([]byte, error) {
+	headSize := 0
+	for _, t := range types {
+		headSize += getTypeSize(*t)
+	}
+	var head, tail []byte
+	for i, t := range types {
+		packed, err := t.pack(values[i])
+		if err != nil {
+			return nil, err
+		}
+		if isDynamicType(*t) {
+			head = append(head, packNum(big.NewInt(int64(headSize+len(tail))))...)
+			tail = append(tail, packed...)
+		} else {
+			head = append(head, packed...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+// packElement packs the given reflect value of the elementary type according to the abi specification in t.
+func packElement(t Type, v reflect.Value) ([]byte, error) {
+	switch t.T {
+	case IntTy, UintTy:
+		n, err := readNum(t, v)
+		if err != nil {
+			return nil, err
+		}
+		return packNum(n), nil
+	case BoolTy:
+		if v.Kind() != reflect.Bool {
+			return nil, typeErr(t, v)
+		}
+		if v.Bool() {
+			return packNum(Big1), nil
+		}
+		return packNum(new(big.Int)), nil
+	case StringTy:
+		if v.Kind() != reflect.String {
+			return nil, typeErr(t, v)
+		}
+		return packBytesSlice([]byte(v.String())), nil
+	case BytesTy:
+		bts, ok := readBytes(v)
+		if !ok {
+			return nil, typeErr(t, v)
+		}
+		return packBytesSlice(bts), nil
+	case AddressTy, FixedBytesTy, HashTy:
+		bts, ok := readBytes(v)
+		if !ok {
+			return nil, typeErr(t, v)
+		}
+		if len(bts) != t.Size {
+			return nil, fmt.Errorf("abi: cannot use %d bytes as type %v", len(bts), t)
+		}
+		word := make([]byte, 32)
+		if t.T == AddressTy {
+			// addresses are padded on the left side like numbers
+			copy(word[32-len(bts):], bts)
+		} else {
+			copy(word, bts)
+		}
+		return word, nil
+	default:
+		return nil, fmt.Errorf("abi: could not pack element, unknown type: %v", t.T)
+	}
+}
+
+// packBytesSlice packs the given bytes as [L, V] as the canonical representation bytes slice.
+func packBytesSlice(bts []byte) []byte {
+	packed := packNum(big.NewInt(int64(len(bts))))
+	padded := make([]byte, (len(bts)+31)/32*32)
+	copy(padded, bts)
+	return append(packed, padded...)
+}
+
+// packNum packs the given number as the 32 bytes word, negative numbers are packed in two's complement.
+func packNum(n *big.Int) []byte {
+	word := make([]byte, 32)
+	if n.Sign() < 0 {
+		n = new(big.Int).Add(n, MaxUint256)
+		n.Add(n, Big1)
+	}
+	return n.FillBytes(word)
+}
+
+// readNum reads the number from the reflect value and checks that it fits into the integer type t.
+func readNum(t Type, v reflect.Value) (*big.Int, error) {
+	var n *big.Int
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = big.NewInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = new(big.Int).SetUint64(v.Uint())
+	case reflect.Ptr:
+		bigInt, ok := v.Interface().(*big.Int)
+		if !ok || bigInt == nil {
+			return nil, typeErr(t, v)
+		}
+		n = bigInt
+	default:
+		return nil, typeErr(t, v)
+	}
+
+	var minValue, maxValue *big.Int
+	if t.T == UintTy {
+		minValue = new(big.Int)
+		maxValue = new(big.Int).Sub(new(big.Int).Lsh(Big1, uint(t.Size)), Big1)
+	} else {
+		minValue = new(big.Int).Neg(new(big.Int).Lsh(Big1, uint(t.Size-1)))
+		maxValue = new(big.Int).Sub(new(big.Int).Lsh(Big1, uint(t.Size-1)), Big1)
+	}
+	if n.Cmp(minValue) < 0 || n.Cmp(maxValue) > 0 {
+		return nil, fmt.Errorf("abi: value %v overflows type %v", n, t)
+	}
+	return n, nil
+}
+
+// readBytes reads the bytes from the bytes slice or bytes array reflect value.
+func readBytes(v reflect.Value) ([]byte, bool) {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, false
+		}
+		return v.Bytes(), true
+	case reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, false
+		}
+		bts := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(bts), v)
+		return bts, true
+	default:
+		return nil, false
+	}
+}
+
+// repeatType returns the slice of size elements of the type t.
+func repeatType(t *Type, size int) []*Type {
+	types := make([]*Type, size)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}
+
+// sequenceValues returns elements of the slice or array reflect value.
+func sequenceValues(v reflect.Value) []reflect.Value {
+	values := make([]reflect.Value, v.Len())
+	for i := range values {
+		values[i] = v.Index(i)
+	}
+	return values
+}
+
+// typeErr returns a formatted type casting error.
+func typeErr(t Type, v reflect.Value) error {
+	return fmt.Errorf("abi: cannot use %v as type %v as argument", v.Type(), t)
+}
@@ -0,0 +1,115 @@
+package fourbyte
+
+import (
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		types  []string
+		values []interface{}
+	}{
+		{
+			types:  []string{"uint8", "int8", "uint64", "int256", "uint256", "bool"},
+			values: []interface{}{uint8(255), int8(-128), uint64(1 << 63), big.NewInt(-1), MaxUint256, true},
+		},
+		{
+			types:  []string{"string", "bytes", "address", "bytes4", "bytes32"},
+			values: []interface{}{"hello world", []byte{1, 2, 3}, common.HexToAddress("0x9a1989946ae4249aac19ac7a038d24aab03c3d8c"), [4]byte{1, 2, 3, 4}, [32]byte{31: 1}},
+		},
+		{
+			types:  []string{"uint256[2]", "string[]", "uint8[2][]", "bytes[2]", "bool"},
+			values: []interface{}{[2]*big.Int{big.NewInt(1), big.NewInt(2)}, []string{"a", "b", "c"}, [][2]uint8{{1, 2}, {3, 4}}, [2][]byte{{1}, {2, 3}}, false},
+		},
+		{
+			types:  []string{"string[]", "uint32[]"},
+			values: []interface{}{[]string{}, []uint32{}},
+		},
+	}
+	for _, tc := range tests {
+		expected := ethPack(t, tc.types, tc.values...)
+		packed, err := newTestArguments(t, tc.types...).Pack(tc.values...)
+		require.NoError(t, err, tc.types)
+		require.Equal(t, expected, packed, tc.types)
+	}
+}
+
+func TestPackRoundTrip(t *testing.T) {
+	types := []string{"(uint256,(bool,string[]),address)[2]", "(bytes32,int64)[]", "(uint8,string)"}
+	args := newTestArguments(t, types...)
+
+	tuple := newStaticTuple(t, args[1].Type.Elem, []string{"bytes32", "int64"}, [32]byte{1}, int64(-5))
+	data, err := args.Pack(
+		[2]struct {
+			A *big.Int
+			B struct {
+				C bool
+				D []string
+			}
+			E Address
+		}{{A: big.NewInt(7)}, {A: big.NewInt(8), E: Address{1}}},
+		[]interface{}{tuple, tuple},
+		struct {
+			A uint8
+			B string
+		}{1, "b"},
+	)
+	require.NoError(t, err)
+
+	values, err := args.UnpackValues(data)
+	require.NoError(t, err)
+	repacked, err := args.Pack(values...)
+	require.NoError(t, err)
+	require.Equal(t, data, repacked)
+}
+
+func TestPackInvalid(t *testing.T) {
+	tests := []struct {
+		typeString string
+		value      interface{}
+	}{
+		{"uint8", 256},
+		{"uint8", -1},
+		{"int8", 128},
+		{"uint256", new(big.Int).Add(MaxUint256, Big1)},
+		{"int256", MaxUint256},
+		{"uint256", (*big.Int)(nil)},
+		{"uint256", "1"},
+		{"bool", 1},
+		{"address", []byte{1, 2, 3}},
+		{"bytes4", [3]byte{}},
+		{"uint8[2]", []uint8{1}},
+		{"(uint8,bool)", struct{ A uint8 }{1}},
+		{"string", nil},
+	}
+	for _, tc := range tests {
+		_, err := newTestArguments(t, tc.typeString).Pack(tc.value)
+		require.Error(t, err, tc.typeString)
+	}
+	_, err := newTestArguments(t, "uint8", "uint8").Pack(uint8(1))
+	require.Error(t, err)
+}
+
+func TestMethodEncodeCall(t *testing.T) {
+	// from https://etherscan.io/tx/0x363f979b58c82614db71229c2a57ed760e7bc454ee29c2f8fd1df99028667ea5
+	expected := "a9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c000000000000000000000000000000000000000000002c5b68601cc92ad60000"
+
+	value, ok := new(big.Int).SetString("209470300000000000000000", 10)
+	require.True(t, ok)
+	transfer := erc20Methods[erc20TransferSignature.Selector()]
+	data, err := transfer.EncodeCall(mustParseAddress(t, "0x9a1989946ae4249AAC19ac7a038d24Aab03c3D8c"), value)
+	require.NoError(t, err)
+	require.Equal(t, expected, hex.EncodeToString(data))
+}
+
+// newStaticTuple creates the go value of the static tuple type from its fields values,
+// static tuples are encoded just like the sequence of their fields.
+func newStaticTuple(t *testing.T, typ *Type, fieldTypes []string, fields ...interface{}) interface{} {
+	values, err := newTestArguments(t, typ.String()).UnpackValues(ethPack(t, fieldTypes, fields...))
+	require.NoError(t, err)
+	return values[0]
+}
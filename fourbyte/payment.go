@@ -0,0 +1,111 @@
+package fourbyte
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PaymentsArgumentName is the name of the payments argument.
+//
+// Methods of Waves dApps accept payments as the last input argument of
+// the "(bytes32,int64)[]" type, i.e. an array of (assetId bytes32, amount int64) tuples.
+// The payments argument is a regular argument, so it's a part of the method signature.
+// It's detected by the type, not by the name, for the methods with the Payments flag, see Method.Payments.
+const PaymentsArgumentName = "payments"
+
+var paymentsType = mustNewTypeWithComponents("tuple[]", []ArgumentMarshaling{
+	{Name: "assetId", Type: "bytes32"},
+	{Name: "amount", Type: "int64"},
+})
+
+// Payment is the Waves invoke-script payment attached to the method call.
+type Payment struct {
+	// AssetID is the id of the payment asset, zero id is used for WAVES.
+	AssetID [32]byte
+	Amount  int64
+}
+
+// IsWaves reports whether the payment is made in WAVES.
+func (p Payment) IsWaves() bool {
+	return p.AssetID == [32]byte{}
+}
+
+func (p Payment) String() string {
+	if p.IsWaves() {
+		return fmt.Sprintf("%d WAVES", p.Amount)
+	}
+	return fmt.Sprintf("%d %x", p.Amount, p.AssetID)
+}
+
+type jsonPayment struct {
+	AssetID string `json:"assetId"`
+	Amount  string `json:"amount"`
+}
+
+// MarshalJSON implements json.Marshaler interface, the asset id is encoded as 0x-prefixed hex string
+// and the amount as decimal string.
+func (p Payment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPayment{AssetID: "0x" + hex.EncodeToString(p.AssetID[:]), Amount: strconv.FormatInt(p.Amount, 10)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	var payment jsonPayment
+	if err := json.Unmarshal(data, &payment); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(payment.AssetID, "0x") {
+		return fmt.Errorf("payment asset id %q without 0x prefix", payment.AssetID)
+	}
+	assetID, err := hex.DecodeString(payment.AssetID[2:])
+	if err != nil {
+		return fmt.Errorf("invalid payment asset id: %v", err)
+	}
+	if len(assetID) != len(p.AssetID) {
+		return fmt.Errorf("invalid payment asset id length %d", len(assetID))
+	}
+	amount, err := strconv.ParseInt(payment.Amount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid payment amount: %v", err)
+	}
+	copy(p.AssetID[:], assetID)
+	p.Amount = amount
+	return nil
+}
+
+// NewPaymentsArgument creates the payments argument which must be the last input of the method accepting payments.
+func NewPaymentsArgument() Argument {
+	return Argument{Name: PaymentsArgumentName, Type: paymentsType}
+}
+
+// hasPayments reports whether the last argument has the type of the payments argument.
+// Argument names don't matter, e.g. the arguments of the method created from the signature don't have names.
+func (arguments Arguments) hasPayments() bool {
+	if len(arguments) == 0 {
+		return false
+	}
+	return arguments[len(arguments)-1].Type.String() == paymentsType.String()
+}
+
+// toPayments converts the decoded payments argument value into payments.
+// Tuple fields names don't matter, e.g. the payments argument of the method created from the signature.
+func toPayments(value interface{}) ([]Payment, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct || v.Type().Elem().NumField() != 2 {
+		return nil, fmt.Errorf("invalid payments value type %T", value)
+	}
+	payments := make([]Payment, v.Len())
+	for i := range payments {
+		tuple := v.Index(i)
+		reflect.Copy(reflect.ValueOf(payments[i].AssetID[:]), tuple.Field(0))
+		payments[i].Amount = tuple.Field(1).Int()
+		if payments[i].Amount <= 0 {
+			return nil, fmt.Errorf("invalid payment %d: amount must be positive, got %d", i, payments[i].Amount)
+		}
+	}
+	return payments, nil
+}
@@ -0,0 +1,72 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseArgDataPayments(t *testing.T) {
+	to := Argument{Name: "to", Type: mustNewType("address")}
+	method := NewMethod("deposit", Callable, Arguments{to, NewPaymentsArgument()}, nil)
+	method.Payments = true
+	require.Equal(t, "deposit(address,(bytes32,int64)[])", method.Sig.String())
+
+	asset := [32]byte{1, 2, 3}
+	address := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	payments := []interface{}{
+		newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, [32]byte{}, int64(100)),
+		newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, asset, int64(5)),
+	}
+	data, err := method.EncodeCall(address, payments)
+	require.NoError(t, err)
+
+	decoded, err := parseArgData(&method, data[selectorLen:])
+	require.NoError(t, err)
+	require.Len(t, decoded.Inputs, 1)
+	require.Equal(t, address, decoded.Inputs[0].DecodedValue())
+	require.Equal(t, []Payment{{Amount: 100}, {AssetID: asset, Amount: 5}}, decoded.Payments)
+	require.True(t, decoded.Payments[0].IsWaves())
+	require.False(t, decoded.Payments[1].IsWaves())
+
+	// payments amounts must be positive
+	payments[1] = newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, asset, int64(-5))
+	data, err = method.EncodeCall(address, payments)
+	require.NoError(t, err)
+	_, err = parseArgData(&method, data[selectorLen:])
+	require.Error(t, err)
+
+	// the argument of the payments type is a regular input of the method without the payments flag
+	other := NewMethod("deposit", Callable, Arguments{to, {Name: "orders", Type: paymentsType}}, nil)
+	decoded, err = parseArgData(&other, data[selectorLen:])
+	require.NoError(t, err)
+	require.Len(t, decoded.Inputs, 2)
+	require.Empty(t, decoded.Payments)
+}
+
+func TestDatabaseRideDAppSignaturePayments(t *testing.T) {
+	payment := newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, [32]byte{}, int64(10))
+	method, err := NewMethodFromSignature("abidumpDeposit(address,(bytes32,int64)[])")
+	require.NoError(t, err)
+	require.False(t, method.Payments)
+	address := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	data, err := method.EncodeCall(address, []interface{}{payment})
+	require.NoError(t, err)
+
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	require.NoError(t, db.AddSelector(method.Sig.String()))
+	dApp := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	require.NoError(t, db.AddRideDApp(dApp, []RideFunction{{Name: "swap"}}))
+
+	// the unnamed trailing argument of the signature method is payments in the Ride dApp context
+	decoded, err := db.ParseContractCallDataNew(dApp, data)
+	require.NoError(t, err)
+	require.Len(t, decoded.Inputs, 1)
+	require.Equal(t, []Payment{{Amount: 10}}, decoded.Payments)
+
+	// and a regular input otherwise
+	decoded, err = db.ParseCallDataNew(data)
+	require.NoError(t, err)
+	require.Len(t, decoded.Inputs, 2)
+	require.Empty(t, decoded.Payments)
+}
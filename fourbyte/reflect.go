@@ -32,3 +32,19 @@ func reflectIntType(unsigned bool, size int) reflect.Type {
 	}
 	return reflect.TypeOf(&big.Int{})
 }
+
+// indirect recursively dereferences the value until it either gets the value
+// or finds a big.Int
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if v.Type() == reflect.TypeOf(&big.Int{}) {
+			return v
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface {
+		// nil interface value
+		return reflect.Value{}
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package fourbyte
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+const (
+	revertErrorSignature Signature = "Error(string)"
+	revertPanicSignature Signature = "Panic(uint256)"
+)
+
+// standardErrors are errors which are generated by the solidity compiler itself.
+var standardErrors = map[Selector]Error{
+	revertErrorSignature.Selector(): NewError("Error", Arguments{{Name: "message", Type: mustNewType("string")}}),
+	revertPanicSignature.Selector(): NewError("Panic", Arguments{{Name: "code", Type: mustNewType("uint256")}}),
+}
+
+// panicCodes holds the meaning of the Panic(uint256) codes of the solidity compiler.
+var panicCodes = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "conversion into non-existent enum value",
+	0x22: "access to incorrectly encoded storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "too much memory allocated",
+	0x51: "call to zero-initialized internal function",
+}
+
+// DecodedRevert is the revert data decoded according to the standard or custom error.
+type DecodedRevert struct {
+	Signature string       `json:"signature"`
+	Name      string       `json:"name"`
+	Inputs    []ArgDecoded `json:"inputs"`
+	// Message is the reason of Error(string) or the meaning of the Panic(uint256) code,
+	// it's empty for custom errors.
+	Message string `json:"message,omitempty"`
+}
+
+func (dr DecodedRevert) String() string {
+	if dr.Message != "" {
+		return fmt.Sprintf("%s: %s", dr.Name, dr.Message)
+	}
+	return DecodedCallData{Signature: dr.Signature, Name: dr.Name, Inputs: dr.Inputs}.String()
+}
+
+// PanicMessage returns the meaning of the Panic(uint256) code.
+func PanicMessage(code *big.Int) string {
+	if code.IsUint64() {
+		if msg, ok := panicCodes[code.Uint64()]; ok {
+			return msg
+		}
+	}
+	return fmt.Sprintf("unknown panic code %#x", code)
+}
+
+// DecodeRevert decodes the revert data using the standard errors and signatures, see Database.DecodeRevert.
+func DecodeRevert(data []byte) (*DecodedRevert, error) {
+	db, err := NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return db.DecodeRevert(data)
+}
+
+// DecodeRevert decodes the revert data. Error(string) and Panic(uint256) are recognized first,
+// then the custom errors of the global ABIs and the candidate signatures of the selector.
+func (db *Database) DecodeRevert(data []byte) (*DecodedRevert, error) {
+	return decodeRevert(data, func(id Selector) ([]Method, error) {
+		return db.snapshot().errorMethodsBySelector(nil, id)
+	})
+}
+
+// DecodeContractRevert decodes the revert data like DecodeRevert,
+// custom errors of the contract ABIs take precedence over the global ones.
+func (db *Database) DecodeContractRevert(contract Address, data []byte) (*DecodedRevert, error) {
+	return decodeRevert(data, func(id Selector) ([]Method, error) {
+		state := db.snapshot()
+		return state.errorMethodsBySelector(state.contractABIs[contract], id)
+	})
+}
+
+// errorMethodsBySelector returns the candidate errors for the selector as methods,
+// since the revert data of errors is encoded like the function call.
+func (state *databaseState) errorMethodsBySelector(contractABIs []*ABI, id Selector) ([]Method, error) {
+	var methods []Method
+	if abiError, ok := standardErrors[id]; ok {
+		methods = append(methods, NewMethod(abiError.RawName, Callable, abiError.Inputs, nil))
+	}
+	for _, abis := range [][]*ABI{contractABIs, state.abis} {
+		if abiError, ok := errorBySelector(abis, id); ok && !containsMethod(methods, abiError.Sig) {
+			methods = append(methods, NewMethod(abiError.RawName, Callable, abiError.Inputs, nil))
+		}
+	}
+	return state.appendSignatureMethods(methods, id)
+}
+
+func decodeRevert(data []byte, methodsBySelector func(id Selector) ([]Method, error)) (*DecodedRevert, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty revert data")
+	}
+	candidates, err := parseCallDataNew(data, methodsBySelector)
+	if err != nil {
+		return nil, err
+	}
+	decoded := candidates[0]
+	revert := &DecodedRevert{Signature: decoded.Signature, Name: decoded.Name, Inputs: decoded.Inputs}
+	switch Signature(decoded.Signature) {
+	case revertErrorSignature:
+		revert.Message = decoded.Inputs[0].DecodedValue().(string)
+	case revertPanicSignature:
+		revert.Message = PanicMessage(decoded.Inputs[0].DecodedValue().(*big.Int))
+	}
+	return revert, nil
+}
@@ -0,0 +1,78 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRevertStandard(t *testing.T) {
+	errorMethod, err := NewMethodFromSignature(revertErrorSignature.String())
+	require.NoError(t, err)
+	data, err := errorMethod.EncodeCall("insufficient balance")
+	require.NoError(t, err)
+	require.Equal(t, "08c379a0", errorMethod.Sig.Selector().Hex())
+
+	revert, err := DecodeRevert(data)
+	require.NoError(t, err)
+	require.Equal(t, "Error", revert.Name)
+	require.Equal(t, "insufficient balance", revert.Message)
+	require.Equal(t, "Error: insufficient balance", revert.String())
+
+	panicMethod, err := NewMethodFromSignature(revertPanicSignature.String())
+	require.NoError(t, err)
+	require.Equal(t, "4e487b71", panicMethod.Sig.Selector().Hex())
+	for code, message := range map[int64]string{
+		0x01: "assertion failed",
+		0x11: "arithmetic overflow or underflow",
+		0x32: "array index out of bounds",
+		0x99: "unknown panic code 0x99",
+	} {
+		data, err := panicMethod.EncodeCall(big.NewInt(code))
+		require.NoError(t, err)
+		revert, err := DecodeRevert(data)
+		require.NoError(t, err)
+		require.Equal(t, "Panic", revert.Name)
+		require.Equal(t, message, revert.Message)
+	}
+
+	_, err = DecodeRevert(nil)
+	require.Error(t, err)
+	_, err = DecodeRevert(data[:20])
+	require.Error(t, err)
+}
+
+func TestDecodeRevertCustomErrors(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	contractABI, err := JSON(strings.NewReader(testJSONABI))
+	require.NoError(t, err)
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	db.AddContractABI(contract, contractABI)
+
+	abiError, err := contractABI.ErrorById(Signature("InsufficientBalance(uint256,uint256)").Selector())
+	require.NoError(t, err)
+	errorMethod := NewMethod(abiError.RawName, Callable, abiError.Inputs, nil)
+	data, err := errorMethod.EncodeCall(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+
+	_, err = db.DecodeRevert(data)
+	require.Error(t, err)
+	revert, err := db.DecodeContractRevert(contract, data)
+	require.NoError(t, err)
+	require.Equal(t, "InsufficientBalance", revert.Name)
+	require.Empty(t, revert.Message)
+	require.Equal(t, big.NewInt(2), revert.Inputs[1].DecodedValue())
+
+	require.NoError(t, db.AddSelector("Unauthorized(address)"))
+	method, err := NewMethodFromSignature("Unauthorized(address)")
+	require.NoError(t, err)
+	caller := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	data, err = method.EncodeCall(caller)
+	require.NoError(t, err)
+	revert, err = db.DecodeRevert(data)
+	require.NoError(t, err)
+	require.Equal(t, "Unauthorized", revert.Name)
+	require.Equal(t, caller, revert.Inputs[0].DecodedValue())
+}
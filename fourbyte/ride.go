@@ -0,0 +1,144 @@
+package fourbyte
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// rideMaxListSize is the maximum number of elements of the Ride list.
+	rideMaxListSize = 1000
+	// rideMinTupleSize and rideMaxTupleSize are bounds of the Ride tuple size.
+	rideMinTupleSize = 2
+	rideMaxTupleSize = 22
+)
+
+var (
+	// rideMaxBigInt and rideMinBigInt are bounds of the Ride BigInt, it is a 512 bit signed integer.
+	rideMaxBigInt = new(big.Int).Sub(new(big.Int).Lsh(Big1, 511), Big1)
+	rideMinBigInt = new(big.Int).Neg(new(big.Int).Lsh(Big1, 511))
+)
+
+var (
+	// ErrRideOverflow is returned when the numeric value doesn't fit into the Ride type.
+	ErrRideOverflow = errors.New("value overflows ride type")
+	// ErrRideSizeLimit is returned when the list or tuple size violates the Ride limits.
+	ErrRideSizeLimit = errors.New("value violates ride size limits")
+	// ErrRideUnsupportedType is returned when the value doesn't have Ride representation.
+	ErrRideUnsupportedType = errors.New("value type has no ride representation")
+)
+
+// RideConversionError describes why the decoded value can't be represented in Ride.
+// Err is one of ErrRideOverflow, ErrRideSizeLimit or ErrRideUnsupportedType.
+type RideConversionError struct {
+	Value interface{}
+	Err   error
+}
+
+func (e *RideConversionError) Error() string {
+	return fmt.Sprintf("failed to convert %T value to ride type: %v", e.Value, e.Err)
+}
+
+func (e *RideConversionError) Unwrap() error {
+	return e.Err
+}
+
+// RideType is the value of the Ride VM value model.
+type RideType interface {
+	fmt.Stringer
+	// InstanceOf returns the Ride type name of the value.
+	InstanceOf() string
+}
+
+// RideInt is the Ride Int, a 64 bit signed integer.
+type RideInt int64
+
+func (v RideInt) InstanceOf() string {
+	return "Int"
+}
+
+func (v RideInt) String() string {
+	return fmt.Sprintf("%d", int64(v))
+}
+
+// RideBigInt is the Ride BigInt, a 512 bit signed integer.
+type RideBigInt struct {
+	V *big.Int
+}
+
+func (v RideBigInt) InstanceOf() string {
+	return "BigInt"
+}
+
+func (v RideBigInt) String() string {
+	return v.V.String()
+}
+
+// RideBoolean is the Ride Boolean.
+type RideBoolean bool
+
+func (v RideBoolean) InstanceOf() string {
+	return "Boolean"
+}
+
+func (v RideBoolean) String() string {
+	return fmt.Sprintf("%t", bool(v))
+}
+
+// RideString is the Ride String.
+type RideString string
+
+func (v RideString) InstanceOf() string {
+	return "String"
+}
+
+func (v RideString) String() string {
+	return fmt.Sprintf("%q", string(v))
+}
+
+// RideByteVector is the Ride ByteVector.
+type RideByteVector []byte
+
+func (v RideByteVector) InstanceOf() string {
+	return "ByteVector"
+}
+
+func (v RideByteVector) String() string {
+	return fmt.Sprintf("base16'%x'", []byte(v))
+}
+
+// RideList is the Ride List[Any].
+type RideList []RideType
+
+func (v RideList) InstanceOf() string {
+	return "List[Any]"
+}
+
+func (v RideList) String() string {
+	return fmt.Sprintf("[%s]", joinRideValues(v))
+}
+
+// RideTuple is the Ride tuple of 2 to 22 elements.
+type RideTuple []RideType
+
+func (v RideTuple) InstanceOf() string {
+	types := make([]string, len(v))
+	for i, elem := range v {
+		types[i] = elem.InstanceOf()
+	}
+	return fmt.Sprintf("(%s)", strings.Join(types, ", "))
+}
+
+func (v RideTuple) String() string {
+	return fmt.Sprintf("(%s)", joinRideValues(v))
+}
+
+func joinRideValues(values []RideType) string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = value.String()
+	}
+	return strings.Join(strs, ", ")
+}
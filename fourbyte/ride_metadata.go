@@ -0,0 +1,113 @@
+package fourbyte
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// RideArgument is the argument of the Ride dApp callable function from the script metadata.
+// Type is the Ride type name, e.g. "Int" or "List[String]".
+type RideArgument struct {
+	Name string
+	Type string
+}
+
+// RideFunction is the Ride dApp callable or verifier function from the script metadata.
+type RideFunction struct {
+	Name      string
+	Arguments []RideArgument
+	// Verifier reports whether the function is the dApp verifier, verifiers don't have arguments.
+	Verifier bool
+}
+
+// rideTypeToABI maps the Ride type of the callable function argument to the ABI type:
+//
+//	Int        -> int64
+//	String     -> string
+//	ByteVector -> bytes
+//	Boolean    -> bool
+//	List[T]    -> T[], where T is one of the types above
+func rideTypeToABI(rideType string) (string, error) {
+	switch rideType {
+	case "Int":
+		return "int64", nil
+	case "String":
+		return "string", nil
+	case "ByteVector":
+		return "bytes", nil
+	case "Boolean":
+		return "bool", nil
+	}
+	if strings.HasPrefix(rideType, "List[") && strings.HasSuffix(rideType, "]") {
+		elem := rideType[len("List[") : len(rideType)-1]
+		if strings.HasPrefix(elem, "List[") {
+			return "", errors.Errorf("nested lists are not supported, got %q", rideType)
+		}
+		elemType, err := rideTypeToABI(elem)
+		if err != nil {
+			return "", err
+		}
+		return elemType + "[]", nil
+	}
+	return "", errors.Errorf("unsupported ride type %q", rideType)
+}
+
+// NewRideMethod creates the method of the Ride dApp callable function.
+// The trailing payments argument is appended to the function arguments, see PaymentsArgumentName.
+// The verifier function is created as the Verifier method without arguments.
+func NewRideMethod(function RideFunction) (Method, error) {
+	if function.Name == "" {
+		return Method{}, errors.New("empty ride function name")
+	}
+	if function.Verifier {
+		if len(function.Arguments) != 0 {
+			return Method{}, errors.Errorf("ride verifier %q can't have arguments", function.Name)
+		}
+		return NewMethod(function.Name, Verifier, nil, nil), nil
+	}
+	inputs := make(Arguments, 0, len(function.Arguments)+1)
+	for _, arg := range function.Arguments {
+		abiType, err := rideTypeToABI(arg.Type)
+		if err != nil {
+			return Method{}, errors.Wrapf(err, "invalid argument %q of ride function %q", arg.Name, function.Name)
+		}
+		typ, err := NewType(abiType)
+		if err != nil {
+			return Method{}, err
+		}
+		inputs = append(inputs, Argument{Name: arg.Name, Type: typ})
+	}
+	inputs = append(inputs, NewPaymentsArgument())
+	method := NewMethod(function.Name, Callable, inputs, nil)
+	method.Payments = true
+	return method, nil
+}
+
+// NewRideABI creates the ABI of the Ride dApp from its callable and verifier functions metadata.
+func NewRideABI(functions []RideFunction) (*ABI, error) {
+	abi := &ABI{Methods: make(map[Selector]Method, len(functions)), Ride: true}
+	for _, function := range functions {
+		method, err := NewRideMethod(function)
+		if err != nil {
+			return nil, err
+		}
+		if method.Type == Verifier {
+			if abi.HasVerifier() {
+				return nil, errors.New("only single verifier is allowed")
+			}
+			abi.Verifier = method
+			continue
+		}
+		selector := method.Sig.Selector()
+		if _, ok := abi.Methods[selector]; ok {
+			return nil, errors.Errorf("duplicate method %q with selector %s", method.Sig, selector)
+		}
+		abi.Methods[selector] = method
+	}
+	if abi.HasVerifier() {
+		if method, ok := abi.Methods[abi.Verifier.Sig.Selector()]; ok {
+			return nil, errors.Errorf("method %q collides with the verifier selector", method.Sig)
+		}
+	}
+	return abi, nil
+}
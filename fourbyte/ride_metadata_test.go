@@ -0,0 +1,112 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRideTypeToABI(t *testing.T) {
+	tests := []struct {
+		rideType string
+		abiType  string
+	}{
+		{"Int", "int64"},
+		{"String", "string"},
+		{"ByteVector", "bytes"},
+		{"Boolean", "bool"},
+		{"List[Int]", "int64[]"},
+		{"List[ByteVector]", "bytes[]"},
+	}
+	for _, tc := range tests {
+		abiType, err := rideTypeToABI(tc.rideType)
+		require.NoError(t, err, tc.rideType)
+		require.Equal(t, tc.abiType, abiType)
+	}
+	for _, rideType := range []string{"", "BigInt", "Int|String", "List[List[Int]]", "List[]", "(Int, String)"} {
+		_, err := rideTypeToABI(rideType)
+		require.Error(t, err, rideType)
+	}
+}
+
+func TestDatabaseRideDApp(t *testing.T) {
+	functions := []RideFunction{
+		{Name: "swap", Arguments: []RideArgument{
+			{Name: "assetId", Type: "String"},
+			{Name: "minAmount", Type: "Int"},
+		}},
+		{Name: "setKeys", Arguments: []RideArgument{
+			{Name: "keys", Type: "List[String]"},
+			{Name: "flag", Type: "Boolean"},
+		}},
+	}
+	method, err := NewRideMethod(functions[0])
+	require.NoError(t, err)
+	require.Equal(t, "swap(string,int64,(bytes32,int64)[])", method.Sig.String())
+
+	payment := newStaticTuple(t, paymentsType.Elem, []string{"bytes32", "int64"}, [32]byte{}, int64(10))
+	data, err := method.EncodeCall("WAVES", int64(1), []interface{}{payment})
+	require.NoError(t, err)
+
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	dApp := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	require.NoError(t, db.AddRideDApp(dApp, functions))
+
+	decoded, err := db.ParseContractCallDataNew(dApp, data)
+	require.NoError(t, err)
+	require.Equal(t, "swap", decoded.Name)
+	require.Len(t, decoded.Inputs, 2)
+	require.Equal(t, []Payment{{Amount: 10}}, decoded.Payments)
+
+	_, err = db.ParseCallDataNew(data)
+	require.Error(t, err)
+
+	functions = append(functions, RideFunction{Name: "swap", Arguments: functions[0].Arguments})
+	require.Error(t, db.AddRideDApp(dApp, functions))
+	require.Error(t, db.AddRideDApp(dApp, []RideFunction{{Name: "f", Arguments: []RideArgument{{Name: "a", Type: "BigInt"}}}}))
+}
+
+func TestDatabaseRideDAppVerifier(t *testing.T) {
+	functions := []RideFunction{
+		{Name: "deposit"},
+		{Name: "myVerifier", Verifier: true},
+	}
+	abi, err := NewRideABI(functions)
+	require.NoError(t, err)
+	require.True(t, abi.HasVerifier())
+	require.Equal(t, Signature(VerifierName+"()"), abi.Verifier.Sig)
+	require.Equal(t, "verifier myVerifier() returns()", abi.Verifier.String())
+
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	data, err := abi.Verifier.EncodeCall()
+	require.NoError(t, err)
+	// the reserved selector isn't resolved to the verifier until a dApp with the verifier is registered
+	if decoded, err := db.ParseCallDataNew(data); err == nil {
+		require.False(t, decoded.Verifier)
+	}
+	other := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	require.NoError(t, db.AddRideDApp(other, functions[:1]))
+	if decoded, err := db.ParseCallDataNew(data); err == nil {
+		require.False(t, decoded.Verifier)
+	}
+
+	dApp := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	require.NoError(t, db.AddRideDApp(dApp, functions))
+	decoded, err := db.ParseContractCallDataNew(dApp, data)
+	require.NoError(t, err)
+	require.True(t, decoded.Verifier)
+	require.Equal(t, "myVerifier", decoded.Name)
+
+	// without the contract the dApp is unknown, the verifier is named by the reserved name
+	decoded, err = db.ParseCallDataNew(data)
+	require.NoError(t, err)
+	require.True(t, decoded.Verifier)
+	require.Equal(t, VerifierName, decoded.Name)
+	require.Equal(t, VerifierName+"()", decoded.Signature)
+
+	_, err = NewRideABI(append(functions, RideFunction{Name: "other", Verifier: true}))
+	require.Error(t, err)
+	_, err = NewRideABI([]RideFunction{{Name: "v", Verifier: true, Arguments: []RideArgument{{Name: "a", Type: "Int"}}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package fourbyte
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestToRideType(t *testing.T) {
+	address := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	method, err := NewMethodFromSignature("f(int8,uint64,uint256,bool,string,bytes,bytes4,address,uint16[],(int64,bool),uint8[],uint8[2],bytes4[1])")
+	require.NoError(t, err)
+	tuple := newStaticTuple(t, &method.Inputs[9].Type, []string{"int64", "bool"}, int64(-1), true)
+	data, err := method.EncodeCall(
+		int8(-8), uint64(64), MaxUint256, true, "str", []byte{1, 2}, [4]byte{1, 2, 3, 4}, address,
+		[]uint16{1, 2}, tuple, []uint8{3, 4}, [2]uint8{5, 6}, [1][4]byte{{7, 8, 9, 10}},
+	)
+	require.NoError(t, err)
+	decoded, err := parseArgData(&method, data[selectorLen:])
+	require.NoError(t, err)
+
+	values, err := decoded.RideInputs()
+	require.NoError(t, err)
+	require.Equal(t, []RideType{
+		RideInt(-8),
+		RideInt(64),
+		RideBigInt{V: MaxUint256},
+		RideBoolean(true),
+		RideString("str"),
+		RideByteVector{1, 2},
+		RideByteVector{1, 2, 3, 4},
+		RideByteVector(address.Bytes()),
+		RideList{RideInt(1), RideInt(2)},
+		RideTuple{RideInt(-1), RideBoolean(true)},
+		// uint8 slices and arrays are lists of integers, not byte vectors
+		RideList{RideInt(3), RideInt(4)},
+		RideList{RideInt(5), RideInt(6)},
+		RideList{RideByteVector{7, 8, 9, 10}},
+	}, values)
+}
+
+func TestToRideTypeErrors(t *testing.T) {
+	tests := []struct {
+		typ   string
+		value interface{}
+		err   error
+	}{
+		{"uint64", uint64(math.MaxInt64 + 1), ErrRideOverflow},
+		{"int256", new(big.Int).Lsh(Big1, 511), ErrRideOverflow},
+		{"bytes", make([]uint8, rideMaxListSize+1), nil},
+		{"uint8[]", make([]uint8, rideMaxListSize+1), ErrRideSizeLimit},
+		{"bool[]", make([]bool, rideMaxListSize+1), ErrRideSizeLimit},
+		{"(bool)", struct{ A bool }{true}, ErrRideSizeLimit},
+		{"uint64[]", []uint64{math.MaxUint64}, ErrRideOverflow},
+		{"uint256", 3.14, ErrRideUnsupportedType},
+		{"string", []byte("str"), ErrRideUnsupportedType},
+		{"bool", nil, ErrRideUnsupportedType},
+	}
+	for _, tc := range tests {
+		_, err := toRideType(mustNewType(tc.typ), tc.value)
+		if tc.err == nil {
+			require.NoError(t, err)
+			continue
+		}
+		var conversionErr *RideConversionError
+		require.True(t, errors.As(err, &conversionErr), tc.typ)
+		require.True(t, errors.Is(err, tc.err), tc.typ)
+	}
+}
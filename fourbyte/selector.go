@@ -2,14 +2,21 @@ package fourbyte
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
+	"io/ioutil"
+	"math/big"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // selectorRegexp is used to validate that a 4byte database selector corresponds
@@ -67,6 +74,7 @@ func (arg *ethDecodedArgument) InternalType() byte {
 
 type ArgDecoded interface {
 	fmt.Stringer
+	json.Marshaler
 	DecodedValue() interface{}
 	InternalType() byte
 }
@@ -89,6 +97,11 @@ type DecodedCallData struct {
 	Signature string
 	Name      string
 	Inputs    []ArgDecoded
+	// Payments are attached to the call via the trailing payments argument,
+	// which is not included into Inputs.
+	Payments []Payment
+	// Verifier reports whether the call data is the invocation of the dApp verifier, see VerifierName.
+	Verifier bool
 }
 
 // String implements stringer interface for decodedCallData
@@ -97,9 +110,45 @@ func (cd DecodedCallData) String() string {
 	for i, arg := range cd.Inputs {
 		args[i] = arg.String()
 	}
+	if len(cd.Payments) != 0 {
+		payments := make([]string, len(cd.Payments))
+		for i, payment := range cd.Payments {
+			payments[i] = payment.String()
+		}
+		return fmt.Sprintf("%s(%s) payments: [%s]", cd.Name, strings.Join(args, ","), strings.Join(payments, ", "))
+	}
 	return fmt.Sprintf("%s(%s)", cd.Name, strings.Join(args, ","))
 }
 
+// RideInputs converts the decoded inputs values into the Ride VM value model.
+func (cd *DecodedCallData) RideInputs() ([]RideType, error) {
+	values := make([]RideType, len(cd.Inputs))
+	for i, arg := range cd.Inputs {
+		typ, err := argumentType(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert input %d", i)
+		}
+		value, err := toRideType(typ, arg.DecodedValue())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert input %d", i)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// argumentType returns the ABI type of the decoded argument.
+func argumentType(arg ArgDecoded) (Type, error) {
+	switch arg := arg.(type) {
+	case *decodedArg:
+		return arg.Soltype.Type, nil
+	case *ethDecodedArgument:
+		return NewType(arg.Soltype.Type.String())
+	default:
+		return Type{}, errors.Errorf("unsupported decoded argument %T", arg)
+	}
+}
+
 func parseCallData(calldata []byte, unescapedAbidata string) (*DecodedCallData, error) {
 	// Validate the call data that it has the 4byte prefix and the rest divisible by 32 bytes
 	if len(calldata) < 4 {
@@ -150,93 +199,599 @@ func parseCallData(calldata []byte, unescapedAbidata string) (*DecodedCallData,
 
 // Database is a 4byte database with the possibility of maintaining an immutable
 // set (embedded) into the process and a mutable set (loaded and written to file).
+// Also it holds registered ABI sets which are used for the native methods decoding,
+// they can be registered for all contracts (global) or for the specific contract.
+//
+// Each selector may have several candidate signatures because of the selector collisions.
+//
+// Database is safe for concurrent use. Lookups work with the immutable snapshot of the database state,
+// updates are serialized and publish the modified copy of the state, so the signatures can be added or
+// reloaded while the call data is being decoded.
 type Database struct {
-	embedded map[string]string
-	custom   map[string]string
+	mu    sync.Mutex   // serializes updates
+	state atomic.Value // holds *databaseState
+}
+
+// databaseState is the snapshot of the database. It's never modified after being published,
+// updates copy the modified maps and slices.
+type databaseState struct {
+	embedded map[string][]string
+	custom   signatureSet
+	// watched is the custom set of the file watched by WatchCustom, it's kept apart from the custom set,
+	// so the reloads don't drop the signatures added by other means.
+	watched signatureSet
+
+	abis         []*ABI
+	contractABIs map[Address][]*ABI
+
+	chainID *big.Int
 }
 
 // New loads the standard signature database embedded in the package.
+// The ERC20 methods ABI is registered as a global ABI.
 func NewDatabase() (*Database, error) {
-	db := &Database{make(map[string]string), make(map[string]string)}
-	db.embedded = __4byteJson
-
+	embedded, err := embedded4byte()
+	if err != nil {
+		return nil, err
+	}
+	db := new(Database)
+	db.state.Store(&databaseState{
+		embedded:     embedded,
+		abis:         []*ABI{{Methods: erc20Methods, Events: erc20Events}},
+		contractABIs: make(map[Address][]*ABI),
+	})
 	return db, nil
 }
 
+// snapshot returns the current state of the database.
+func (db *Database) snapshot() *databaseState {
+	return db.state.Load().(*databaseState)
+}
+
+// update applies the modification to the copy of the current state and publishes the copy
+// if the modification succeeds. The copy shares maps and slices with the current state,
+// so the modification must replace them instead of changing them in place.
+func (db *Database) update(modify func(state *databaseState) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	state := *db.snapshot()
+	if err := modify(&state); err != nil {
+		return err
+	}
+	db.state.Store(&state)
+	return nil
+}
+
+// SetChainID sets the expected chain ID of the decoded transactions, see ChainIDStatus.
+func (db *Database) SetChainID(chainID *big.Int) {
+	if chainID != nil {
+		chainID = new(big.Int).Set(chainID)
+	}
+	_ = db.update(func(state *databaseState) error {
+		state.chainID = chainID
+		return nil
+	})
+}
+
+// AddABI registers the ABI methods for all contracts.
+// ABIs registered later take precedence over the earlier registered ones.
+// The ABI must not be modified after the registration.
+func (db *Database) AddABI(abi *ABI) {
+	_ = db.update(func(state *databaseState) error {
+		state.abis = appendABI(state.abis, abi)
+		return nil
+	})
+}
+
+// AddContractABI registers the ABI methods only for the given contract.
+// Contract ABIs take precedence over the global ABIs. The ABI must not be modified after the registration.
+func (db *Database) AddContractABI(contract Address, abi *ABI) {
+	_ = db.update(func(state *databaseState) error {
+		contractABIs := make(map[Address][]*ABI, len(state.contractABIs)+1)
+		for address, abis := range state.contractABIs {
+			contractABIs[address] = abis
+		}
+		contractABIs[contract] = appendABI(contractABIs[contract], abi)
+		state.contractABIs = contractABIs
+		return nil
+	})
+}
+
+// appendABI appends the ABI to the copy of the slice, so the slice of the published state stays intact.
+func appendABI(abis []*ABI, abi *ABI) []*ABI {
+	return append(abis[:len(abis):len(abis)], abi)
+}
+
+// AddRideDApp registers the methods of the Ride dApp callable functions for the dApp address,
+// see NewRideABI.
+func (db *Database) AddRideDApp(dApp Address, functions []RideFunction) error {
+	abi, err := NewRideABI(functions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ABI of ride dApp %s", dApp.Hex())
+	}
+	db.AddContractABI(dApp, abi)
+	return nil
+}
+
+// AddSelector validates the function signature and inserts it into the custom set.
+// The selector of the signature is recomputed from the canonical signature representation.
+func (db *Database) AddSelector(signature string) error {
+	method, err := NewMethodFromSignature(signature)
+	if err != nil {
+		return err
+	}
+	hexSelector := method.Sig.Selector().Hex()
+	return db.update(func(state *databaseState) error {
+		state.custom = state.custom.with(map[string][]string{hexSelector: {method.Sig.String()}})
+		return nil
+	})
+}
+
+// LoadCustom loads the custom set from the file in the 4byte.json format (hex selector → signature).
+// Each signature is validated by recomputing its selector. The current custom set is replaced
+// only if all the signatures are valid.
+func (db *Database) LoadCustom(path string) error {
+	custom, err := loadCustomFile(path)
+	if err != nil {
+		return err
+	}
+	return db.update(func(state *databaseState) error {
+		state.custom = signatureSet{}.with(custom)
+		return nil
+	})
+}
+
+// loadWatched replaces the watched set with the signatures of the file content, see WatchCustom.
+func (db *Database) loadWatched(path string, content []byte) error {
+	watched, err := parseCustomFile(path, content)
+	if err != nil {
+		return err
+	}
+	return db.update(func(state *databaseState) error {
+		state.watched = signatureSet{}.with(watched)
+		return nil
+	})
+}
+
+// loadCustomFile loads and validates the signatures of the file in the 4byte.json format.
+func loadCustomFile(path string) (map[string][]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read custom signatures file %q", path)
+	}
+	return parseCustomFile(path, content)
+}
+
+// parseCustomFile validates the signatures of the file content in the 4byte.json format.
+func parseCustomFile(path string, content []byte) (map[string][]string, error) {
+	signatures, err := Load4byteJSON(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load custom signatures file %q", path)
+	}
+	custom, err := canonicalSignatures(signatures)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid custom signatures file %q", path)
+	}
+	return custom, nil
+}
+
+// WatchCustom loads the signatures from the file like LoadCustom and then reloads them in the background
+// whenever the content of the file changes. The file is polled with the given interval until the context
+// is done, the changes are detected by the content hash, so the rewrites within the modification time
+// granularity are not missed. Reload errors are reported to onError if it's not nil,
+// the current signatures of the file are kept then.
+//
+// The signatures of the watched file are kept apart from the custom set and are ranked after it,
+// so the reloads replace only the signatures of the file. SaveCustom doesn't write them.
+func (db *Database) WatchCustom(ctx context.Context, path string, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		return errors.Errorf("non-positive custom signatures file poll interval %v", interval)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read custom signatures file %q", path)
+	}
+	if err := db.loadWatched(path, content); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			content, err := ioutil.ReadFile(path)
+			if err == nil {
+				current := sha256.Sum256(content)
+				if current == sum {
+					continue
+				}
+				sum = current
+				err = db.loadWatched(path, content)
+			} else {
+				err = errors.Wrapf(err, "failed to read custom signatures file %q", path)
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// AddSignatures validates the signatures in the 4byte.json format (hex selector → signatures)
+// like LoadCustom and merges them into the custom set. Nothing is added if any signature is invalid.
+func (db *Database) AddSignatures(signatures map[string][]string) error {
+	canonical, err := canonicalSignatures(signatures)
+	if err != nil {
+		return err
+	}
+	return db.update(func(state *databaseState) error {
+		state.custom = state.custom.with(canonical)
+		return nil
+	})
+}
+
+// canonicalSignatures validates each signature by recomputing its selector
+// and converts the signatures to the canonical representation.
+func canonicalSignatures(signatures map[string][]string) (map[string][]string, error) {
+	canonical := make(map[string][]string, len(signatures))
+	for hexSelector, candidates := range signatures {
+		for _, signature := range candidates {
+			method, err := NewMethodFromSignature(signature)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid custom signature for selector %s", hexSelector)
+			}
+			if selector := method.Sig.Selector(); selector.Hex() != hexSelector {
+				return nil, errors.Errorf("custom signature %q has selector %s, but stored with selector %s",
+					signature, selector.Hex(), hexSelector,
+				)
+			}
+			canonical[hexSelector] = append(canonical[hexSelector], method.Sig.String())
+		}
+	}
+	return canonical, nil
+}
+
+// SaveCustom writes the custom set to the file in the 4byte.json format (hex selector → signatures).
+func (db *Database) SaveCustom(path string) error {
+	blob, err := json.MarshalIndent(db.snapshot().custom.toMap(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal custom signatures")
+	}
+	if err := ioutil.WriteFile(path, blob, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write custom signatures file %q", path)
+	}
+	return nil
+}
+
+// Selector returns the best ranked candidate signature for the 4-byte id, see Selectors.
 // This method does not validate the match, it's assumed the caller will do.
 func (db *Database) Selector(id []byte) (string, error) {
+	signatures, err := db.Selectors(id)
+	if err != nil {
+		return "", err
+	}
+	return signatures[0], nil
+}
+
+// Selectors returns all candidate signatures for the 4-byte id. The candidates are ranked:
+// the custom signatures go first, then the signatures of the watched file, see WatchCustom,
+// then the embedded ones in the order of the 4byte.json dump,
+// i.e. signatures submitted earlier to the 4byte.directory take precedence.
+// This method does not validate the match, it's assumed the caller will do.
+func (db *Database) Selectors(id []byte) ([]string, error) {
+	return db.snapshot().selectors(id)
+}
+
+func (state *databaseState) selectors(id []byte) ([]string, error) {
 	if len(id) < 4 {
-		return "", fmt.Errorf("expected 4-byte id, got %d", len(id))
+		return nil, fmt.Errorf("expected 4-byte id, got %d", len(id))
 	}
 	sig := hex.EncodeToString(id[:4])
-	if selector, exists := db.embedded[sig]; exists {
-		return selector, nil
+	var signatures []string
+	for _, candidates := range [][]string{state.custom.get(sig), state.watched.get(sig), state.embedded[sig]} {
+		for _, candidate := range candidates {
+			if !containsString(signatures, candidate) {
+				signatures = append(signatures, candidate)
+			}
+		}
 	}
-	if selector, exists := db.custom[sig]; exists {
-		return selector, nil
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("signature %v not found", sig)
+	}
+	return signatures, nil
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
 	}
-	return "", fmt.Errorf("signature %v not found", sig)
+	return false
 }
 
+// MethodBySelector returns the best ranked candidate method for the selector, see MethodsBySelector.
 func (db *Database) MethodBySelector(id Selector) (Method, error) {
-	if method, ok := erc20Methods[id]; ok {
-		return method, nil
+	methods, err := db.MethodsBySelector(id)
+	if err != nil {
+		return Method{}, err
 	}
-	// TODO(nickeskov): support ride scripts metadata
-	return Method{}, fmt.Errorf("signature %v not found", id.String())
+	return methods[0], nil
 }
 
-// ValidateCallData checks if the ABI call-data + method selector (if given) can
-// be parsed and seems to match.
-func (db *Database) ParseCallData(data []byte) (*DecodedCallData, error) {
+// MethodsBySelector returns all candidate methods for the selector. The method of the global ABIs
+// goes first, then the methods built from the candidate signatures, see Selectors.
+// Methods built from the signatures don't have arguments names.
+//
+// The reserved verifier selector, see VerifierName, is resolved to the verifier if any of the registered
+// Ride dApps has one. The dApp is unknown without the contract, so the verifier is named by the reserved
+// name, use ContractMethodsBySelector to get the verifier of the particular dApp.
+func (db *Database) MethodsBySelector(id Selector) ([]Method, error) {
+	return db.snapshot().methodsBySelector(id)
+}
 
-	// If the data is empty, we have a plain value transfer, nothing more to do
-	if len(data) == 0 {
-		return nil, errors.New("transaction doesn't contain data")
+func (state *databaseState) methodsBySelector(id Selector) ([]Method, error) {
+	var methods []Method
+	if method, ok := methodBySelector(state.abis, id); ok {
+		methods = append(methods, method)
 	}
-	// Validate the call data that it has the 4byte prefix and the rest divisible by 32 bytes
-	if len(data) < 4 {
-		return nil, errors.New("transaction data is not valid ABI: missing the 4 byte call prefix")
+	if id == verifierSelector && state.hasRideVerifier() && !containsMethod(methods, verifierSignature) {
+		methods = append(methods, NewMethod(VerifierName, Verifier, nil, nil))
 	}
-	if n := len(data) - 4; n%32 != 0 {
-		return nil, errors.Errorf("transaction data is not valid ABI (length should be a multiple of 32 (was %d))", n)
+	return state.appendSignatureMethods(methods, id)
+}
+
+var (
+	// verifierSignature is the reserved signature of the Ride dApp verifiers, see VerifierName.
+	verifierSignature = NewSignature(VerifierName, nil)
+	verifierSelector  = verifierSignature.Selector()
+)
+
+// hasRideVerifier reports whether any of the registered Ride dApps has the verifier.
+func (state *databaseState) hasRideVerifier() bool {
+	for _, abis := range state.contractABIs {
+		for _, abi := range abis {
+			if abi.Ride && abi.HasVerifier() {
+				return true
+			}
+		}
 	}
-	embedded, err := db.Selector(data[:4])
+	return false
+}
+
+// ContractMethodBySelector returns the best ranked candidate method for the selector,
+// see ContractMethodsBySelector.
+func (db *Database) ContractMethodBySelector(contract Address, id Selector) (Method, error) {
+	methods, err := db.ContractMethodsBySelector(contract, id)
 	if err != nil {
-		return nil, errors.Errorf("Transaction contains data, but the ABI signature could not be found: %v", err)
+		return Method{}, err
+	}
+	return methods[0], nil
+}
+
+// ContractMethodsBySelector returns all candidate methods for the selector. The method of the
+// contract ABIs goes first, then the candidates of the global ABIs and signatures, see MethodsBySelector.
+// If the contract is the Ride dApp, the candidates with the trailing payments argument get the Payments flag.
+func (db *Database) ContractMethodsBySelector(contract Address, id Selector) ([]Method, error) {
+	state := db.snapshot()
+	var methods []Method
+	if method, ok := methodBySelector(state.contractABIs[contract], id); ok {
+		methods = append(methods, method)
 	}
-	info, err := verifySelector(embedded, data)
+	if method, ok := methodBySelector(state.abis, id); ok && !containsMethod(methods, method.Sig) {
+		methods = append(methods, method)
+	}
+	methods, err := state.appendSignatureMethods(methods, id)
 	if err != nil {
-		return nil, errors.Errorf("Transaction contains data, but provided ABI signature could not be verified: %v", err)
+		return nil, err
+	}
+	if isRideDApp(state.contractABIs[contract]) {
+		// methods are copies, so the registered ABIs are not affected
+		for i := range methods {
+			methods[i].Payments = methods[i].Inputs.hasPayments()
+		}
 	}
-	return info, nil
+	return methods, nil
+}
 
+// isRideDApp reports whether any of the contract ABIs belongs to the Ride dApp.
+func isRideDApp(abis []*ABI) bool {
+	for _, abi := range abis {
+		if abi.Ride {
+			return true
+		}
+	}
+	return false
 }
 
-func (db *Database) ParseCallDataNew(data []byte) (*DecodedCallData, error) {
+// appendSignatureMethods appends methods built from the candidate signatures of the selector.
+// Signatures which can't be parsed or which are already present in methods are skipped.
+func (state *databaseState) appendSignatureMethods(methods []Method, id Selector) ([]Method, error) {
+	signatures, err := state.selectors(id[:])
+	if err != nil && len(methods) == 0 {
+		return nil, err
+	}
+	for _, signature := range signatures {
+		if containsMethod(methods, Signature(signature)) {
+			continue
+		}
+		method, err := NewMethodFromSignature(signature)
+		if err != nil {
+			// some junk signatures from the dump may use unsupported types
+			continue
+		}
+		methods = append(methods, method)
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("signature %v not found", id.String())
+	}
+	return methods, nil
+}
+
+func containsMethod(methods []Method, sig Signature) bool {
+	for i := range methods {
+		if methods[i].Sig == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// methodBySelector looks up the method in the given ABIs starting from the last one.
+func methodBySelector(abis []*ABI, id Selector) (Method, bool) {
+	for i := len(abis) - 1; i >= 0; i-- {
+		if method, err := abis[i].MethodById(id); err == nil {
+			return method, true
+		}
+	}
+	return Method{}, false
+}
+
+// eventByID looks up the event in the given ABIs starting from the last one.
+func eventByID(abis []*ABI, topic Hash) (Event, bool) {
+	for i := len(abis) - 1; i >= 0; i-- {
+		if event, ok := abis[i].Events[topic]; ok {
+			return event, true
+		}
+	}
+	return Event{}, false
+}
+
+// errorBySelector looks up the custom error in the given ABIs starting from the last one.
+func errorBySelector(abis []*ABI, id Selector) (Error, bool) {
+	for i := len(abis) - 1; i >= 0; i-- {
+		if abiError, ok := abis[i].Errors[id]; ok {
+			return abiError, true
+		}
+	}
+	return Error{}, false
+}
+
+// validateCallData checks that the call data has the 4byte prefix and the rest divisible by 32 bytes.
+func validateCallData(data []byte) error {
 	// If the data is empty, we have a plain value transfer, nothing more to do
 	if len(data) == 0 {
-		return nil, errors.New("transaction doesn't contain data")
+		return errors.New("transaction doesn't contain data")
 	}
 	// Validate the call data that it has the 4byte prefix and the rest divisible by 32 bytes
 	if len(data) < 4 {
-		return nil, errors.New("transaction data is not valid ABI: missing the 4 byte call prefix")
+		return errors.New("transaction data is not valid ABI: missing the 4 byte call prefix")
 	}
 	if n := len(data) - 4; n%32 != 0 {
-		return nil, errors.Errorf("transaction data is not valid ABI (length should be a multiple of 32 (was %d))", n)
+		return errors.Errorf("transaction data is not valid ABI (length should be a multiple of 32 (was %d))", n)
 	}
-	var selector Selector
-	copy(selector[:], data[:len(selector)])
-	method, err := db.MethodBySelector(selector)
+	return nil
+}
+
+// ParseCallData decodes the call data with the candidate signatures of the selector
+// and returns the best ranked candidate which matches the data, see ParseCallDataCandidates.
+func (db *Database) ParseCallData(data []byte) (*DecodedCallData, error) {
+	candidates, err := db.ParseCallDataCandidates(data)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[0], nil
+}
+
+// ParseCallDataCandidates decodes the call data with each candidate signature of the selector.
+// Candidates which fail to decode or don't round-trip are dropped, the survivors are returned
+// in the order of Selectors ranking.
+func (db *Database) ParseCallDataCandidates(data []byte) ([]*DecodedCallData, error) {
+	if err := validateCallData(data); err != nil {
+		return nil, err
+	}
+	signatures, err := db.Selectors(data[:4])
 	if err != nil {
 		return nil, errors.Errorf("Transaction contains data, but the ABI signature could not be found: %v", err)
 	}
+	var (
+		decoded       []*DecodedCallData
+		candidatesErr = &CandidatesError{
+			Signatures: make([]Signature, 0, len(signatures)),
+			Errs:       make([]error, 0, len(signatures)),
+		}
+	)
+	for _, signature := range signatures {
+		info, err := verifySelector(signature, data)
+		if err != nil {
+			candidatesErr.Signatures = append(candidatesErr.Signatures, Signature(signature))
+			candidatesErr.Errs = append(candidatesErr.Errs, err)
+			continue
+		}
+		decoded = append(decoded, info)
+	}
+	if len(decoded) == 0 {
+		return nil, candidatesErr
+	}
+	return decoded, nil
+}
+
+// ParseCallDataNew decodes the call data natively using the methods of the global ABIs and signatures
+// and the verifiers of the Ride dApps, see MethodsBySelector.
+// The best ranked candidate is returned, see ParseCallDataNewCandidates.
+func (db *Database) ParseCallDataNew(data []byte) (*DecodedCallData, error) {
+	candidates, err := db.ParseCallDataNewCandidates(data)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[0], nil
+}
+
+// ParseCallDataNewCandidates decodes the call data natively with each candidate method of the selector.
+// Candidates which fail to decode are dropped, the survivors are returned in the order of
+// MethodsBySelector ranking.
+func (db *Database) ParseCallDataNewCandidates(data []byte) ([]*DecodedCallData, error) {
+	return parseCallDataNew(data, db.MethodsBySelector)
+}
+
+// ParseContractCallDataNew decodes the call data natively using the methods of the contract
+// and global ABIs and signatures. The best ranked candidate is returned.
+func (db *Database) ParseContractCallDataNew(contract Address, data []byte) (*DecodedCallData, error) {
+	candidates, err := parseCallDataNew(data, func(id Selector) ([]Method, error) {
+		return db.ContractMethodsBySelector(contract, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candidates[0], nil
+}
 
-	info, err := parseArgData(&method, data[len(selector):])
+func parseCallDataNew(data []byte, methodsBySelector func(id Selector) ([]Method, error)) ([]*DecodedCallData, error) {
+	if err := validateCallData(data); err != nil {
+		return nil, err
+	}
+	var selector Selector
+	copy(selector[:], data[:len(selector)])
+	methods, err := methodsBySelector(selector)
 	if err != nil {
-		return nil, errors.Errorf("Transaction contains data, but provided ABI signature could not be verified: %v", err)
+		return nil, errors.Errorf("Transaction contains data, but the ABI signature could not be found: %v", err)
+	}
+	var (
+		decoded       []*DecodedCallData
+		candidatesErr = &CandidatesError{
+			Signatures: make([]Signature, 0, len(methods)),
+			Errs:       make([]error, 0, len(methods)),
+		}
+	)
+	for i := range methods {
+		info, err := parseArgData(&methods[i], data[len(selector):])
+		if err != nil {
+			candidatesErr.Signatures = append(candidatesErr.Signatures, methods[i].Sig)
+			candidatesErr.Errs = append(candidatesErr.Errs, err)
+			continue
+		}
+		decoded = append(decoded, info)
 	}
-	return info, nil
+	if len(decoded) == 0 {
+		return nil, candidatesErr
+	}
+	return decoded, nil
 }
 
 // verifySelector checks whether the ABI encoded data blob matches the requested
@@ -254,6 +809,9 @@ func verifySelector(functionSignature string, calldata []byte) (*DecodedCallData
 type decodedArg struct {
 	Soltype Argument
 	Value   interface{}
+	// Hashed reports whether the Value is the keccak256 Hash of the indexed event argument
+	// instead of the value of the argument type, see isHashedTopic.
+	Hashed bool
 }
 
 func (da *decodedArg) String() string {
@@ -264,6 +822,9 @@ func (da *decodedArg) String() string {
 	default:
 		value = fmt.Sprintf("%v", val)
 	}
+	if da.Hashed {
+		return fmt.Sprintf("%v: hash %v", da.Soltype.Type.String(), value)
+	}
 	return fmt.Sprintf("%v: %v", da.Soltype.Type.String(), value)
 }
 
@@ -271,7 +832,11 @@ func (da *decodedArg) DecodedValue() interface{} {
 	return da.Value
 }
 
+// InternalType returns the type of the decoded value, it's HashTy for the hashed values.
 func (da *decodedArg) InternalType() byte {
+	if da.Hashed {
+		return byte(HashTy)
+	}
 	return byte(da.Soltype.Type.T)
 }
 
@@ -285,13 +850,239 @@ func parseArgData(method *Method, argData []byte) (*DecodedCallData, error) {
 		return nil, errors.Wrap(err, "failed to unpack Inputs arguments ABI data")
 	}
 
+	inputs := len(method.Inputs)
+	if method.Payments && method.Inputs.hasPayments() {
+		inputs--
+	}
 	// TODO(nickeskov): use our types
-	decoded := DecodedCallData{Signature: method.Sig.String(), Name: method.RawName}
-	for i := 0; i < len(method.Inputs); i++ {
+	decoded := DecodedCallData{Signature: method.Sig.String(), Name: method.RawName, Verifier: method.Type == Verifier}
+	for i := 0; i < inputs; i++ {
 		decoded.Inputs = append(decoded.Inputs, &decodedArg{
 			Soltype: method.Inputs[i],
 			Value:   values[i],
 		})
 	}
+	// We're finished decoding the data. At this point, we encode the decoded data
+	// to see if it matches with the original data. If we didn't do that, it would
+	// be possible to stuff extra data into the arguments, which is not detected
+	// by merely decoding the data.
+	encoded, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack decoded Inputs arguments")
+	}
+	if ranges := diffRanges(encoded, argData, selectorLen); len(ranges) != 0 {
+		return nil, &StuffedDataError{Signature: method.Sig, Ranges: ranges}
+	}
+	if inputs < len(method.Inputs) {
+		decoded.Payments, err = toPayments(values[inputs])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode payments")
+		}
+	}
 	return &decoded, nil
 }
+
+// ByteRange is the half-open [Start, End) range of bytes.
+type ByteRange struct {
+	Start int
+	End   int
+}
+
+func (r ByteRange) String() string {
+	return fmt.Sprintf("[%d, %d)", r.Start, r.End)
+}
+
+// StuffedDataError is returned when the call data doesn't match the canonical encoding of the decoded values,
+// e.g. it has trailing garbage or non-canonical offsets. Ranges hold the unexpected bytes of the call data,
+// offsets include the 4-byte selector. It's also returned for the log data of events and for the return data
+// of methods, offsets are relative to the data then, see DecodeLog and Method.DecodeReturn.
+type StuffedDataError struct {
+	Signature Signature
+	Ranges    []ByteRange
+}
+
+func (e *StuffedDataError) Error() string {
+	ranges := make([]string, len(e.Ranges))
+	for i, r := range e.Ranges {
+		ranges[i] = r.String()
+	}
+	return fmt.Sprintf("supplied data is stuffed with extra data for %v, unexpected bytes at %s",
+		e.Signature, strings.Join(ranges, ", "),
+	)
+}
+
+// CandidatesError is returned when none of the candidate signatures of the selector matches the call data.
+// Errs hold the decoding errors of the candidates in the order of Signatures, errors.As looks through
+// all of them, so the typed errors such as StuffedDataError can be extracted.
+type CandidatesError struct {
+	Signatures []Signature
+	Errs       []error
+}
+
+func (e *CandidatesError) Error() string {
+	errs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = fmt.Sprintf("%q: %v", e.Signatures[i], err)
+	}
+	return fmt.Sprintf("Transaction contains data, but provided ABI signature could not be verified: %s",
+		strings.Join(errs, "; "),
+	)
+}
+
+// Unwrap returns the errors of the candidates.
+func (e *CandidatesError) Unwrap() []error {
+	return e.Errs
+}
+
+// As finds the first error of the candidates which matches the target, see errors.As.
+func (e *CandidatesError) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAlignedWords limits the size of the table used to align the differing words in diffRanges,
+// the words are compared position by position beyond it.
+const maxAlignedWords = 1 << 20
+
+// diffRanges returns the ranges of the actual data which differ from the expected canonical encoding.
+// The data are compared by 32-byte words aligned by their longest common subsequence, so the unexpected
+// words don't shift the rest of the data. Each changed word, e.g. a non-canonical offset slot, is reported
+// as a separate range, while the words inserted between the expected ones, e.g. the gap skipped by
+// a non-canonical offset, and the trailing bytes are reported as the whole ranges. The expected words missing
+// at the end of the actual data are reported as the range beyond it. Ranges are shifted by the given offset.
+func diffRanges(expected, actual []byte, offset int) []ByteRange {
+	var ranges []ByteRange
+	appendRange := func(start, end int) {
+		ranges = append(ranges, ByteRange{Start: start + offset, End: end + offset})
+	}
+	expectedWords, actualWords := dataWords(expected), dataWords(actual)
+	// the common prefix and suffix are skipped, so only the differing words are aligned
+	prefix := 0
+	for prefix < len(expectedWords) && prefix < len(actualWords) && expectedWords[prefix] == actualWords[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(expectedWords)-prefix && suffix < len(actualWords)-prefix &&
+		expectedWords[len(expectedWords)-1-suffix] == actualWords[len(actualWords)-1-suffix] {
+		suffix++
+	}
+	missing := 0
+	for _, h := range alignWords(expectedWords[prefix:len(expectedWords)-suffix], actualWords[prefix:len(actualWords)-suffix]) {
+		start := (prefix + h.actual) * 32
+		changed := h.deleted
+		if h.inserted < changed {
+			changed = h.inserted
+		}
+		for i := 0; i < changed; i++ {
+			appendRange(start+i*32, start+(i+1)*32)
+		}
+		if h.inserted > changed {
+			appendRange(start+changed*32, start+h.inserted*32)
+		}
+		if h.deleted > changed && prefix+h.actual+h.inserted == len(actualWords) {
+			missing = (h.deleted - changed) * 32
+		}
+	}
+	if tail := len(actual) % 32; tail != 0 {
+		appendRange(len(actual)-tail, len(actual))
+	}
+	if missing != 0 {
+		appendRange(len(actual), len(actual)+missing)
+	}
+	if len(ranges) == 0 && len(expected) > len(actual) {
+		// the expected words are skipped in the middle of the actual data, e.g. due to the overlapping offsets
+		appendRange(len(actual), len(expected))
+	}
+	return ranges
+}
+
+// dataWords splits the data into 32-byte words, the trailing bytes of the incomplete word are dropped.
+func dataWords(data []byte) []string {
+	words := make([]string, len(data)/32)
+	for i := range words {
+		words[i] = string(data[i*32 : (i+1)*32])
+	}
+	return words
+}
+
+// wordsHunk is the run of the expected words deleted and the actual words inserted in their place,
+// actual is the index of the first inserted word.
+type wordsHunk struct {
+	actual   int
+	deleted  int
+	inserted int
+}
+
+// alignWords returns the hunks of the differing words in the order of the actual words.
+func alignWords(expected, actual []string) []wordsHunk {
+	if len(expected) == 0 && len(actual) == 0 {
+		return nil
+	}
+	if len(expected) == 0 || len(actual) == 0 {
+		return []wordsHunk{{deleted: len(expected), inserted: len(actual)}}
+	}
+	if len(expected)*len(actual) > maxAlignedWords {
+		return compareWords(expected, actual)
+	}
+	var hunks []wordsHunk
+	// common[i][j] is the length of the longest common subsequence of expected[i:] and actual[j:]
+	width := len(actual) + 1
+	common := make([]int32, (len(expected)+1)*width)
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			switch {
+			case expected[i] == actual[j]:
+				common[i*width+j] = common[(i+1)*width+j+1] + 1
+			case common[(i+1)*width+j] >= common[i*width+j+1]:
+				common[i*width+j] = common[(i+1)*width+j]
+			default:
+				common[i*width+j] = common[i*width+j+1]
+			}
+		}
+	}
+	hunk := wordsHunk{}
+	flush := func(next int) {
+		if hunk.deleted != 0 || hunk.inserted != 0 {
+			hunks = append(hunks, hunk)
+		}
+		hunk = wordsHunk{actual: next}
+	}
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			i, j = i+1, j+1
+			flush(j)
+		case j == len(actual) || (i < len(expected) && common[(i+1)*width+j] >= common[i*width+j+1]):
+			hunk.deleted++
+			i++
+		default:
+			hunk.inserted++
+			j++
+		}
+	}
+	flush(j)
+	return hunks
+}
+
+// compareWords returns the hunks of the differing words compared position by position.
+func compareWords(expected, actual []string) []wordsHunk {
+	var hunks []wordsHunk
+	common := len(expected)
+	if len(actual) < common {
+		common = len(actual)
+	}
+	for i := 0; i < common; i++ {
+		if expected[i] != actual[i] {
+			hunks = append(hunks, wordsHunk{actual: i, deleted: 1, inserted: 1})
+		}
+	}
+	if len(expected) != len(actual) {
+		hunks = append(hunks, wordsHunk{actual: common, deleted: len(expected) - common, inserted: len(actual) - common})
+	}
+	return hunks
+}
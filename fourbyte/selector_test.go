@@ -0,0 +1,453 @@
+package fourbyte
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDatabaseRegisteredABI(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+
+	submitSig := Signature("submit((address,uint256[2],bytes32)[])")
+	type order struct {
+		Maker   common.Address
+		Amounts [2]*big.Int
+		Salt    [32]byte
+	}
+	orders := []order{{
+		Maker:   common.HexToAddress("0x9a1989946ae4249aac19ac7a038d24aab03c3d8c"),
+		Amounts: [2]*big.Int{big.NewInt(10), big.NewInt(20)},
+		Salt:    [32]byte{1},
+	}}
+	ordersType, err := abi.NewType("tuple[]", "", []abi.ArgumentMarshaling{
+		{Name: "maker", Type: "address"},
+		{Name: "amounts", Type: "uint256[2]"},
+		{Name: "salt", Type: "bytes32"},
+	})
+	require.NoError(t, err)
+	args, err := abi.Arguments{{Type: ordersType}}.Pack(orders)
+	require.NoError(t, err)
+	selector := submitSig.Selector()
+	data := append(selector[:], args...)
+
+	_, err = db.ParseCallDataNew(data)
+	require.Error(t, err)
+
+	contractABI, err := JSON(strings.NewReader(testJSONABI))
+	require.NoError(t, err)
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	db.AddContractABI(contract, contractABI)
+
+	_, err = db.ParseCallDataNew(data)
+	require.Error(t, err)
+	decoded, err := db.ParseContractCallDataNew(contract, data)
+	require.NoError(t, err)
+	require.Equal(t, submitSig.String(), decoded.Signature)
+	require.Equal(t, "submit", decoded.Name)
+
+	db.AddABI(contractABI)
+	decoded, err = db.ParseCallDataNew(data)
+	require.NoError(t, err)
+	require.Equal(t, submitSig.String(), decoded.Signature)
+
+	// registered ABI takes precedence over the embedded ERC20 methods
+	method, err := db.MethodBySelector(erc20TransferSignature.Selector())
+	require.NoError(t, err)
+	require.Equal(t, "function transfer(address _to, uint256 _value) returns(bool)", method.String())
+}
+
+func TestDatabaseCustomSignatures(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+
+	require.Error(t, db.AddSelector("setFee(address,uint7)"))
+	require.Error(t, db.AddSelector("setFee"))
+	require.NoError(t, db.AddSelector("setFee(address,uint)"))
+
+	setFeeSelector := Signature("setFee(address,uint256)").Selector()
+	signature, err := db.Selector(setFeeSelector[:])
+	require.NoError(t, err)
+	require.Equal(t, "setFee(address,uint256)", signature)
+
+	path := filepath.Join(t.TempDir(), "4byte_custom.json")
+	require.NoError(t, db.SaveCustom(path))
+
+	loaded, err := NewDatabase()
+	require.NoError(t, err)
+	require.NoError(t, loaded.LoadCustom(path))
+	method, err := loaded.MethodBySelector(setFeeSelector)
+	require.NoError(t, err)
+	require.Equal(t, Signature("setFee(address,uint256)"), method.Sig)
+
+	// selector of the signature doesn't match the stored one
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"a9059cbb": "setFee(address,uint256)"}`), 0600))
+	require.Error(t, loaded.LoadCustom(path))
+	// the previous custom set is kept
+	_, err = loaded.Selector(setFeeSelector[:])
+	require.NoError(t, err)
+
+	require.Error(t, loaded.AddSignatures(map[string][]string{"a9059cbb": {"setFee(address,uint256)"}}))
+	require.NoError(t, loaded.AddSignatures(map[string][]string{
+		setFeeSelector.Hex(): {"setFee(address,uint)"},
+		"a9059cbb":           {"transfer(address,uint)"},
+	}))
+	signatures, err := loaded.Selectors(setFeeSelector[:])
+	require.NoError(t, err)
+	require.Equal(t, []string{"setFee(address,uint256)"}, signatures)
+	signature, err = loaded.Selector([]byte{0xa9, 0x05, 0x9c, 0xbb})
+	require.NoError(t, err)
+	require.Equal(t, "transfer(address,uint256)", signature)
+}
+
+func TestDatabaseSnapshotIsolation(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	require.NoError(t, db.AddSelector("setFee(address,uint256)"))
+	setFeeSelector := Signature("setFee(address,uint256)").Selector()
+	setOwnerSelector := Signature("abidumpSetOwner(address)").Selector()
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+
+	state := db.snapshot()
+	require.NoError(t, db.AddSelector("abidumpSetOwner(address)"))
+	require.NoError(t, db.AddSignatures(map[string][]string{setFeeSelector.Hex(): {"setFee(address,uint256)"}}))
+	db.AddContractABI(contract, &ABI{Methods: erc20Methods})
+	db.SetChainID(big.NewInt(1))
+
+	// updates are not visible to the previously taken snapshot
+	_, err = state.selectors(setOwnerSelector[:])
+	require.Error(t, err)
+	signatures, err := state.selectors(setFeeSelector[:])
+	require.NoError(t, err)
+	require.Equal(t, []string{"setFee(address,uint256)"}, signatures)
+	require.Empty(t, state.contractABIs)
+	require.Nil(t, state.chainID)
+
+	_, err = db.Selectors(setOwnerSelector[:])
+	require.NoError(t, err)
+	require.Len(t, db.snapshot().contractABIs[contract], 1)
+}
+
+func TestDatabaseConcurrentUpdates(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	transferMethod := erc20Methods[erc20TransferSignature.Selector()]
+	transfer, err := transferMethod.EncodeCall(mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c"), big.NewInt(1000))
+	require.NoError(t, err)
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	path := filepath.Join(t.TempDir(), "4byte_custom.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"a9059cbb": "transfer(address,uint256)"}`), 0600))
+
+	const updates = 200
+	var (
+		done    = make(chan struct{})
+		readers sync.WaitGroup
+	)
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				decoded, err := db.ParseCallDataNew(transfer)
+				if err != nil || decoded.Signature != erc20TransferSignature.String() {
+					t.Errorf("unexpected decoding result %v: %v", decoded, err)
+					return
+				}
+				if _, err := db.ParseContractCallDataNew(contract, transfer); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := db.ParseCallData(transfer); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := db.DecodeRevert([]byte{1, 2, 3, 4}); err == nil {
+					t.Error("unexpected decoding of unknown revert data")
+					return
+				}
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	writers.Add(3)
+	go func() {
+		defer writers.Done()
+		for i := 0; i < updates; i++ {
+			if err := db.AddSelector(fmt.Sprintf("concurrent%d(uint256)", i)); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < updates; i++ {
+			db.AddContractABI(contract, &ABI{Methods: erc20Methods})
+			db.SetChainID(big.NewInt(int64(i)))
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < updates; i++ {
+			if err := db.AddSignatures(map[string][]string{"a9059cbb": {"transfer(address,uint256)"}}); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	// no update is lost
+	for i := 0; i < updates; i++ {
+		sig := Signature(fmt.Sprintf("concurrent%d(uint256)", i))
+		selector := sig.Selector()
+		signature, err := db.Selector(selector[:])
+		require.NoError(t, err)
+		require.Equal(t, sig.String(), signature)
+	}
+	require.Len(t, db.snapshot().contractABIs[contract], updates)
+	require.Equal(t, int64(updates-1), db.snapshot().chainID.Int64())
+	require.Len(t, db.snapshot().custom.get("a9059cbb"), 1)
+}
+
+func TestDatabaseWatchCustom(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "4byte_custom.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.Error(t, db.WatchCustom(ctx, path, time.Millisecond, nil))
+	require.Error(t, db.WatchCustom(ctx, path, 0, nil))
+
+	setFeeSelector := Signature("abidumpSetFee(address,uint256)").Selector()
+	setOwnerSelector := Signature("abidumpSetOwner(address)").Selector()
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"`+setFeeSelector.Hex()+`": "abidumpSetFee(address,uint256)"}`), 0600))
+	// signatures added by other means are kept apart from the watched file, e.g. abidump serve -signatures
+	setNameSelector := Signature("abidumpSetName(string)").Selector()
+	require.NoError(t, db.AddSignatures(map[string][]string{setNameSelector.Hex(): {"abidumpSetName(string)"}}))
+	errs := make(chan error, 100)
+	require.NoError(t, db.WatchCustom(ctx, path, time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+	_, err = db.Selector(setFeeSelector[:])
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"`+setOwnerSelector.Hex()+`": "abidumpSetOwner(address)"}`), 0600))
+	require.Eventually(t, func() bool {
+		_, err := db.Selector(setOwnerSelector[:])
+		return err == nil
+	}, 5*time.Second, time.Millisecond)
+	// the signatures of the file are replaced, the custom set is kept
+	_, err = db.Selector(setFeeSelector[:])
+	require.Error(t, err)
+	_, err = db.Selector(setNameSelector[:])
+	require.NoError(t, err)
+
+	// the rewrite of the same size right after the previous one is detected by the content hash
+	setAdminSelector := Signature("abidumpSetAdmin(address)").Selector()
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"`+setAdminSelector.Hex()+`": "abidumpSetAdmin(address)"}`), 0600))
+	require.Eventually(t, func() bool {
+		_, err := db.Selector(setAdminSelector[:])
+		return err == nil
+	}, 5*time.Second, time.Millisecond)
+	_, err = db.Selector(setOwnerSelector[:])
+	require.Error(t, err)
+
+	// invalid file is reported and the current custom set is kept
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"a9059cbb": "abidumpSetAdmin(address,address)"}`), 0600))
+	select {
+	case err := <-errs:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload error is not reported")
+	}
+	_, err = db.Selector(setAdminSelector[:])
+	require.NoError(t, err)
+}
+
+func TestEmbeddedSignatures(t *testing.T) {
+	embedded, err := embedded4byte()
+	require.NoError(t, err)
+	require.NotEmpty(t, embedded)
+	for hexSelector, signatures := range embedded {
+		for _, signature := range signatures {
+			require.Equal(t, Signature(signature).Selector().Hex(), hexSelector, signature)
+		}
+	}
+
+	// well-known non-ERC20 selectors of the public dump and the colliding signatures of the extra set
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	for _, signature := range []string{
+		"withdraw(uint256)",
+		"deposit()",
+		"mint(address,uint256)",
+		"safeTransferFrom(address,address,uint256)",
+		"setApprovalForAll(address,bool)",
+		"multicall(bytes[])",
+		"swapExactETHForTokens(uint256,address[],address,uint256)",
+	} {
+		selector := Signature(signature).Selector()
+		signatures, err := db.Selectors(selector[:])
+		require.NoError(t, err, signature)
+		require.Equal(t, signature, signatures[0])
+	}
+	require.Greater(t, len(embedded["a9059cbb"]), 2)
+
+	signatures, err := Load4byteJSON(strings.NewReader(`{
+		"0xA9059CBB": "transfer(address,uint256)",
+		"095ea7b3": ["approve(address,uint256)", "sign_szabo_bytecode(bytes16,uint128)"]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"a9059cbb": {"transfer(address,uint256)"},
+		"095ea7b3": {"approve(address,uint256)", "sign_szabo_bytecode(bytes16,uint128)"},
+	}, signatures)
+	_, err = Load4byteJSON(strings.NewReader(`{"a9059c": "transfer(address,uint256)"}`))
+	require.Error(t, err)
+}
+
+func TestDatabaseSelectorCollisions(t *testing.T) {
+	// from https://etherscan.io/tx/0x363f979b58c82614db71229c2a57ed760e7bc454ee29c2f8fd1df99028667ea5
+	data, err := hex.DecodeString("a9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c000000000000000000000000000000000000000000002c5b68601cc92ad60000")
+	require.NoError(t, err)
+
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	signatures, err := db.Selectors(data[:4])
+	require.NoError(t, err)
+	require.Greater(t, len(signatures), 2)
+	require.Equal(t, erc20TransferSignature.String(), signatures[0])
+
+	// junk signatures which don't match the data are dropped
+	expected := []string{"transfer(address,uint256)", "workMyDirefulOwner(uint256,uint256)"}
+	candidates, err := db.ParseCallDataCandidates(data)
+	require.NoError(t, err)
+	require.Equal(t, expected, decodedSignatures(candidates))
+
+	candidates, err = db.ParseCallDataNewCandidates(data)
+	require.NoError(t, err)
+	require.Equal(t, expected, decodedSignatures(candidates))
+	require.Equal(t, "_to", candidates[0].Inputs[0].(*decodedArg).Soltype.Name)
+
+	// custom signatures are ranked first
+	require.NoError(t, db.AddSelector("workMyDirefulOwner(uint256,uint256)"))
+	decoded, err := db.ParseCallData(data)
+	require.NoError(t, err)
+	require.Equal(t, "workMyDirefulOwner(uint256,uint256)", decoded.Signature)
+}
+
+func decodedSignatures(decoded []*DecodedCallData) []string {
+	signatures := make([]string, len(decoded))
+	for i := range decoded {
+		signatures[i] = decoded[i].Signature
+	}
+	return signatures
+}
+
+func TestParseCallDataNewStuffedData(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+
+	// from https://etherscan.io/tx/0x363f979b58c82614db71229c2a57ed760e7bc454ee29c2f8fd1df99028667ea5
+	data, err := hex.DecodeString("a9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c000000000000000000000000000000000000000000002c5b68601cc92ad60000")
+	require.NoError(t, err)
+	stuffed := append(append([]byte{}, data...), make([]byte, 31)...)
+	stuffed = append(stuffed, 0xff)
+
+	_, err = db.ParseCallDataNew(stuffed)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected bytes at [68, 100)")
+	// the typed errors of the candidates are reachable from the public entry points
+	var (
+		candidatesErr *CandidatesError
+		stuffedErr    *StuffedDataError
+	)
+	require.True(t, errors.As(err, &candidatesErr))
+	require.Equal(t, len(candidatesErr.Signatures), len(candidatesErr.Errs))
+	require.True(t, errors.As(err, &stuffedErr))
+	require.Equal(t, erc20TransferSignature, stuffedErr.Signature)
+	require.Equal(t, []ByteRange{{Start: 68, End: 100}}, stuffedErr.Ranges)
+	contract := mustParseAddress(t, "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c")
+	_, err = db.ParseContractCallDataNew(contract, stuffed)
+	require.True(t, errors.As(err, &stuffedErr))
+	results := db.ParseCallDataBatch([]BatchCall{{Data: stuffed}}, 1)
+	require.True(t, errors.As(results[0].Err, &stuffedErr))
+
+	transfer := erc20Methods[erc20TransferSignature.Selector()]
+	_, err = parseArgData(&transfer, stuffed[selectorLen:])
+	require.True(t, errors.As(err, &stuffedErr))
+	require.Equal(t, []ByteRange{{Start: 68, End: 100}}, stuffedErr.Ranges)
+
+	// the string offset points to 0x40 instead of the canonical 0x20, the offset slot and the skipped gap are unexpected
+	method, err := NewMethodFromSignature("setName(string)")
+	require.NoError(t, err)
+	canonical, err := method.EncodeCall("name")
+	require.NoError(t, err)
+	nonCanonical := append(append([]byte{}, canonical[:selectorLen]...), packNum(big.NewInt(0x40))...)
+	nonCanonical = append(nonCanonical, make([]byte, 32)...)
+	nonCanonical = append(nonCanonical, canonical[selectorLen+32:]...)
+	_, err = parseArgData(&method, nonCanonical[selectorLen:])
+	require.True(t, errors.As(err, &stuffedErr))
+	require.Equal(t, []ByteRange{{Start: 4, End: 36}, {Start: 36, End: 68}}, stuffedErr.Ranges)
+}
+
+func TestDiffRanges(t *testing.T) {
+	word := func(b byte) []byte {
+		w := make([]byte, 32)
+		w[31] = b
+		return w
+	}
+	words := func(bs ...byte) []byte {
+		var data []byte
+		for _, b := range bs {
+			data = append(data, word(b)...)
+		}
+		return data
+	}
+	tests := []struct {
+		name     string
+		expected []byte
+		actual   []byte
+		ranges   []ByteRange
+	}{
+		{"equal", words(1, 2, 3), words(1, 2, 3), nil},
+		{"changed word", words(1, 2, 3), words(1, 9, 3), []ByteRange{{36, 68}}},
+		{"changed words", words(1, 2, 3, 4), words(9, 2, 9, 4), []ByteRange{{4, 36}, {68, 100}}},
+		{"adjacent changed words", words(1, 2, 3), words(1, 8, 9), []ByteRange{{36, 68}, {68, 100}}},
+		{"inserted gap", words(1, 2, 3), words(1, 8, 9, 2, 3), []ByteRange{{36, 100}}},
+		{"changed slot and gap", words(1, 2, 3), words(5, 8, 2, 3), []ByteRange{{4, 36}, {36, 68}}},
+		{"trailing word", words(1, 2), words(1, 2, 3), []ByteRange{{68, 100}}},
+		{"trailing bytes", words(1, 2), append(words(1, 2), 0, 0xff), []ByteRange{{68, 70}}},
+		{"changed word and trailing bytes", words(1, 2), append(words(1, 9), 0xff), []ByteRange{{36, 68}, {68, 69}}},
+		{"missing tail", words(1, 2, 3), words(1, 2), []ByteRange{{68, 100}}},
+		{"changed word and missing tail", words(1, 2, 3), words(1, 9), []ByteRange{{36, 68}, {68, 100}}},
+		{"skipped words", words(1, 2, 3), words(1, 3), []ByteRange{{68, 100}}},
+		{"empty", words(1), nil, []ByteRange{{4, 36}}},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.ranges, diffRanges(test.expected, test.actual, selectorLen), test.name)
+	}
+}
@@ -0,0 +1,97 @@
+package fourbyte
+
+import "encoding/hex"
+
+// signatureSet is the persistent set of candidate signatures keyed by the hex selector.
+// The set is never modified in place: with returns the modified copy which shares the untouched
+// shards with the original, so the database snapshots can hold the set without copying it
+// and adding signatures one by one doesn't copy the whole set on each update.
+//
+// The signatures are sharded by the first two bytes of the selector.
+type signatureSet struct {
+	shards *[256]*[256]map[string][]string
+	size   int
+}
+
+// signatureShard returns the shard indexes of the hex selector.
+func signatureShard(hexSelector string) (byte, byte) {
+	var prefix [2]byte
+	if len(hexSelector) >= 4 {
+		hex.Decode(prefix[:], []byte(hexSelector[:4]))
+	}
+	return prefix[0], prefix[1]
+}
+
+// get returns the candidate signatures of the hex selector. The result must not be modified.
+func (set signatureSet) get(hexSelector string) []string {
+	if set.shards == nil {
+		return nil
+	}
+	i, j := signatureShard(hexSelector)
+	if set.shards[i] == nil {
+		return nil
+	}
+	return set.shards[i][j][hexSelector]
+}
+
+// with returns the copy of the set with the new signatures, duplicates are skipped.
+// Only the shards touched by the signatures are copied, each of them once,
+// so the signatures should be added in batches when possible.
+func (set signatureSet) with(signatures map[string][]string) signatureSet {
+	shards := new([256]*[256]map[string][]string)
+	if set.shards != nil {
+		*shards = *set.shards
+	}
+	size := set.size
+	var copied [256]bool
+	copiedLeaves := make(map[[2]byte]bool)
+	for hexSelector, candidates := range signatures {
+		i, j := signatureShard(hexSelector)
+		if !copied[i] {
+			inner := new([256]map[string][]string)
+			if shards[i] != nil {
+				*inner = *shards[i]
+			}
+			shards[i] = inner
+			copied[i] = true
+		}
+		if !copiedLeaves[[2]byte{i, j}] {
+			leaf := make(map[string][]string, len(shards[i][j])+1)
+			for k, v := range shards[i][j] {
+				leaf[k] = v
+			}
+			shards[i][j] = leaf
+			copiedLeaves[[2]byte{i, j}] = true
+		}
+		leaf := shards[i][j]
+		for _, signature := range candidates {
+			existing, ok := leaf[hexSelector]
+			if !ok {
+				size++
+			}
+			if !containsString(existing, signature) {
+				leaf[hexSelector] = append(existing[:len(existing):len(existing)], signature)
+			}
+		}
+	}
+	return signatureSet{shards: shards, size: size}
+}
+
+// toMap returns the signatures of the set in the 4byte.json format (hex selector → signatures).
+func (set signatureSet) toMap() map[string][]string {
+	signatures := make(map[string][]string, set.size)
+	if set.shards == nil {
+		return signatures
+	}
+	for _, inner := range set.shards {
+		if inner == nil {
+			continue
+		}
+		for _, leaf := range inner {
+			for hexSelector, candidates := range leaf {
+				signatures[hexSelector] = candidates
+			}
+		}
+	}
+	return signatures
+}
@@ -0,0 +1,46 @@
+package fourbyte
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSignatureSet(t *testing.T) {
+	var empty signatureSet
+	require.Nil(t, empty.get("a9059cbb"))
+	require.Empty(t, empty.toMap())
+
+	set := empty.with(map[string][]string{"a9059cbb": {"transfer(address,uint256)"}})
+	require.Equal(t, []string{"transfer(address,uint256)"}, set.get("a9059cbb"))
+	require.Nil(t, empty.get("a9059cbb"))
+
+	// the original set is not modified, duplicates are skipped
+	updated := set.with(map[string][]string{
+		"a9059cbb": {"transfer(address,uint256)", "abidumpTransfer(address,uint256)"},
+		"a905ffff": {"abidumpSameShard()"},
+		"095ea7b3": {"approve(address,uint256)"},
+	})
+	require.Equal(t, []string{"transfer(address,uint256)"}, set.get("a9059cbb"))
+	require.Nil(t, set.get("a905ffff"))
+	require.Nil(t, set.get("095ea7b3"))
+	require.Equal(t, map[string][]string{
+		"a9059cbb": {"transfer(address,uint256)", "abidumpTransfer(address,uint256)"},
+		"a905ffff": {"abidumpSameShard()"},
+		"095ea7b3": {"approve(address,uint256)"},
+	}, updated.toMap())
+
+	// the untouched shards are shared
+	require.Same(t, updated.shards[0x09], updated.with(map[string][]string{"a9059cbb": {"abidumpOther()"}}).shards[0x09])
+}
+
+func TestSignatureSetAddOneByOne(t *testing.T) {
+	const selectors = 100000
+	var set signatureSet
+	for i := 0; i < selectors; i++ {
+		hexSelector := fmt.Sprintf("%08x", i*40503)
+		set = set.with(map[string][]string{hexSelector: {fmt.Sprintf("abidump%d()", i)}})
+	}
+	require.Len(t, set.toMap(), selectors)
+	require.Equal(t, []string{"abidump1()"}, set.get(fmt.Sprintf("%08x", 40503)))
+}
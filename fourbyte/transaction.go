@@ -0,0 +1,148 @@
+package fourbyte
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// Transaction types of the EIP-2718 typed transactions envelope.
+const (
+	LegacyTxType     = types.LegacyTxType
+	AccessListTxType = types.AccessListTxType
+	DynamicFeeTxType = types.DynamicFeeTxType
+)
+
+// ChainIDStatus is the outcome of the transaction chain ID validation.
+type ChainIDStatus byte
+
+const (
+	// ChainIDUnprotected is the status of legacy transactions without EIP-155 replay protection.
+	ChainIDUnprotected ChainIDStatus = iota
+	// ChainIDUnchecked is the status of protected transactions when the expected chain ID is not set.
+	ChainIDUnchecked
+	// ChainIDValid is the status of transactions with the expected chain ID.
+	ChainIDValid
+	// ChainIDMismatch is the status of transactions with the chain ID other than the expected one.
+	ChainIDMismatch
+)
+
+func (s ChainIDStatus) String() string {
+	switch s {
+	case ChainIDUnprotected:
+		return "unprotected"
+	case ChainIDUnchecked:
+		return "unchecked"
+	case ChainIDValid:
+		return "valid"
+	case ChainIDMismatch:
+		return "mismatch"
+	default:
+		return fmt.Sprintf("ChainIDStatus(%d)", byte(s))
+	}
+}
+
+// AccessTuple is the element of the EIP-2930 access list.
+type AccessTuple struct {
+	Address     Address
+	StorageKeys []Hash
+}
+
+// DecodedTransaction is the Ethereum transaction with the decoded input.
+type DecodedTransaction struct {
+	Type byte
+	Hash Hash
+	// ChainID is nil for legacy transactions without EIP-155 replay protection.
+	ChainID       *big.Int
+	ChainIDStatus ChainIDStatus
+	// Sender is recovered from the transaction signature, it's nil for unsigned transactions.
+	Sender *Address
+	Nonce  uint64
+	// GasPrice is the gas price of legacy and EIP-2930 transactions,
+	// for EIP-1559 transactions it's equal to GasFeeCap.
+	GasPrice *big.Int
+	// GasTipCap and GasFeeCap are the EIP-1559 fees,
+	// for other transactions both are equal to GasPrice.
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *Address // nil for the contract creation
+	Value      *big.Int
+	AccessList []AccessTuple
+	Data       []byte
+	// Input is the decoded Data, it's nil for the contract creation, for the empty data
+	// and if the data can't be decoded.
+	Input *DecodedCallData
+	// InputErr is the reason why the non-empty Data of the call can't be decoded, e.g. unknown selector.
+	InputErr error
+}
+
+// DecodeTransaction decodes the binary encoded legacy or typed transaction and its input,
+// see Database.DecodeTransaction.
+func DecodeTransaction(raw []byte) (*DecodedTransaction, error) {
+	db, err := NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return db.DecodeTransaction(raw)
+}
+
+// DecodeTransaction decodes the binary encoded transaction: RLP list for legacy transactions
+// or EIP-2718 envelope for typed transactions. The input is decoded natively using
+// the contract ABIs of the recipient, see ParseContractCallDataNew. The input decoding failure
+// doesn't fail the transaction decoding, it's reported in the InputErr field instead.
+// The sender is recovered from the signature with the chain ID of the transaction,
+// the chain ID is validated against the expected one, see SetChainID.
+func (db *Database) DecodeTransaction(raw []byte) (*DecodedTransaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "failed to decode transaction")
+	}
+	decoded := &DecodedTransaction{
+		Type:      tx.Type(),
+		Hash:      Hash(tx.Hash()),
+		Nonce:     tx.Nonce(),
+		GasPrice:  tx.GasPrice(),
+		GasTipCap: tx.GasTipCap(),
+		GasFeeCap: tx.GasFeeCap(),
+		Gas:       tx.Gas(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}
+	if tx.Protected() {
+		decoded.ChainID = tx.ChainId()
+	}
+	expectedChainID := db.snapshot().chainID
+	switch {
+	case decoded.ChainID == nil:
+		decoded.ChainIDStatus = ChainIDUnprotected
+	case expectedChainID == nil:
+		decoded.ChainIDStatus = ChainIDUnchecked
+	case expectedChainID.Cmp(decoded.ChainID) == 0:
+		decoded.ChainIDStatus = ChainIDValid
+	default:
+		decoded.ChainIDStatus = ChainIDMismatch
+	}
+	if _, r, s := tx.RawSignatureValues(); r.Sign() != 0 || s.Sign() != 0 {
+		sender, err := types.Sender(types.LatestSignerForChainID(decoded.ChainID), tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to recover sender of transaction %s", decoded.Hash)
+		}
+		decoded.Sender = (*Address)(&sender)
+	}
+	for _, tuple := range tx.AccessList() {
+		keys := make([]Hash, len(tuple.StorageKeys))
+		for i, key := range tuple.StorageKeys {
+			keys[i] = Hash(key)
+		}
+		decoded.AccessList = append(decoded.AccessList, AccessTuple{Address: Address(tuple.Address), StorageKeys: keys})
+	}
+	if to := tx.To(); to != nil {
+		decoded.To = (*Address)(to)
+		if len(decoded.Data) != 0 {
+			decoded.Input, decoded.InputErr = db.ParseContractCallDataNew(*decoded.To, decoded.Data)
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,127 @@
+package fourbyte
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+const testPrivateKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func signTestTransaction(t *testing.T, chainID *big.Int, txData types.TxData) []byte {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), txData)
+	require.NoError(t, err)
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return raw
+}
+
+func testSender(t *testing.T) Address {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.NoError(t, err)
+	return Address(crypto.PubkeyToAddress(key.PublicKey))
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	chainID := big.NewInt(1)
+	token := common.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+	recipient := mustParseAddress(t, "0x9a1989946ae4249aac19ac7a038d24aab03c3d8c")
+	transfer := erc20Methods[erc20TransferSignature.Selector()]
+	data, err := transfer.EncodeCall(recipient, big.NewInt(1000))
+	require.NoError(t, err)
+	accessList := types.AccessList{{Address: token, StorageKeys: []common.Hash{{1}}}}
+
+	tests := []struct {
+		txType byte
+		txData types.TxData
+	}{
+		{LegacyTxType, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 60000, To: &token, Data: data}},
+		{AccessListTxType, &types.AccessListTx{ChainID: chainID, Nonce: 1, GasPrice: big.NewInt(10), Gas: 60000, To: &token, Data: data, AccessList: accessList}},
+		{DynamicFeeTxType, &types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(10), Gas: 60000, To: &token, Data: data, AccessList: accessList}},
+	}
+	for _, tc := range tests {
+		tx, err := DecodeTransaction(signTestTransaction(t, chainID, tc.txData))
+		require.NoError(t, err, tc.txType)
+		require.Equal(t, tc.txType, tx.Type)
+		require.Equal(t, chainID, tx.ChainID)
+		require.Equal(t, ChainIDUnchecked, tx.ChainIDStatus)
+		require.Equal(t, testSender(t), *tx.Sender)
+		require.Equal(t, uint64(1), tx.Nonce)
+		require.Equal(t, uint64(60000), tx.Gas)
+		require.Equal(t, big.NewInt(10), tx.GasFeeCap)
+		require.Equal(t, Address(token), *tx.To)
+		require.Equal(t, data, tx.Data)
+		require.Equal(t, erc20TransferSignature.String(), tx.Input.Signature)
+		require.Equal(t, recipient, tx.Input.Inputs[0].DecodedValue())
+		if tc.txType != LegacyTxType {
+			require.Equal(t, []AccessTuple{{Address: Address(token), StorageKeys: []Hash{{1}}}}, tx.AccessList)
+		}
+	}
+
+	// contract creation doesn't have the decoded input
+	tx, err := DecodeTransaction(signTestTransaction(t, chainID, &types.LegacyTx{GasPrice: big.NewInt(1), Gas: 100000, Data: []byte{0x60, 0x80}}))
+	require.NoError(t, err)
+	require.Nil(t, tx.To)
+	require.Nil(t, tx.Input)
+	require.NoError(t, tx.InputErr)
+
+	// unknown selector doesn't fail the transaction decoding
+	unknown := append([]byte{0xde, 0xad, 0xbe, 0xef}, make([]byte, 32)...)
+	tx, err = DecodeTransaction(signTestTransaction(t, chainID, &types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(1), Gas: 100000, To: &token, Value: big.NewInt(5), Data: unknown}))
+	require.NoError(t, err)
+	require.Equal(t, testSender(t), *tx.Sender)
+	require.Equal(t, uint64(7), tx.Nonce)
+	require.Equal(t, big.NewInt(5), tx.Value)
+	require.Equal(t, ChainIDUnchecked, tx.ChainIDStatus)
+	require.Equal(t, unknown, tx.Data)
+	require.Nil(t, tx.Input)
+	require.Error(t, tx.InputErr)
+	require.Contains(t, tx.InputErr.Error(), "signature deadbeef not found")
+
+	_, err = DecodeTransaction([]byte{0x02, 0xc0})
+	require.Error(t, err)
+}
+
+func TestDecodeTransactionSender(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	txData := &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &common.Address{1}, Value: big.NewInt(1)}
+	raw := signTestTransaction(t, big.NewInt(1), txData)
+
+	db.SetChainID(big.NewInt(1))
+	tx, err := db.DecodeTransaction(raw)
+	require.NoError(t, err)
+	require.Equal(t, ChainIDValid, tx.ChainIDStatus)
+	require.Equal(t, testSender(t), *tx.Sender)
+
+	// the sender is recovered with the chain ID of the transaction
+	db.SetChainID(big.NewInt(5))
+	tx, err = db.DecodeTransaction(raw)
+	require.NoError(t, err)
+	require.Equal(t, ChainIDMismatch, tx.ChainIDStatus)
+	require.Equal(t, big.NewInt(1), tx.ChainID)
+	require.Equal(t, testSender(t), *tx.Sender)
+
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.NoError(t, err)
+	unprotected, err := types.SignNewTx(key, types.HomesteadSigner{}, txData)
+	require.NoError(t, err)
+	raw, err = unprotected.MarshalBinary()
+	require.NoError(t, err)
+	tx, err = db.DecodeTransaction(raw)
+	require.NoError(t, err)
+	require.Equal(t, ChainIDUnprotected, tx.ChainIDStatus)
+	require.Nil(t, tx.ChainID)
+	require.Equal(t, testSender(t), *tx.Sender)
+
+	raw, err = types.NewTx(txData).MarshalBinary()
+	require.NoError(t, err)
+	tx, err = db.DecodeTransaction(raw)
+	require.NoError(t, err)
+	require.Nil(t, tx.Sender)
+}
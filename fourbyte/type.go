@@ -2,8 +2,11 @@ package fourbyte
 
 import (
 	"fmt"
-	"github.com/ethereum/go-ethereum/common"
+	"go/token"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 type ArgT byte
@@ -17,20 +20,21 @@ const (
 	StringTy
 	SliceTy
 	BytesTy
-	//ArrayTy
 	TupleTy
 
 	AddressTy // nickeskov: we use this type only for erc20 transfers
 
-	//FixedBytesTy
-	//HashTy
+	// new kinds are appended to keep the values of the existing ones
+	ArrayTy
+	FixedBytesTy
+	HashTy // nickeskov: values which are available only as their keccak256 hash
 	//FixedPointTy
 	//FunctionTy
 )
 
 // Type is the reflection of the supported argument type.
 type Type struct {
-	Elem *Type // nested types for SliceTy
+	Elem *Type // nested types for SliceTy and ArrayTy
 	Size int
 	T    ArgT // Our own type checking
 
@@ -43,7 +47,324 @@ type Type struct {
 	TupleType     reflect.Type // Underlying struct of the tuple
 }
 
-func (t *Type) String() string {
+var (
+	// elementaryTypeRegexp parses the elementary abi types, e.g. "uint256" or "bytes32"
+	elementaryTypeRegexp = regexp.MustCompile(`^([a-z]+)([0-9]*)$`)
+)
+
+// maxStaticSize is the maximum size of the in-place encoding of the fixed-size arrays and tuples.
+// Signatures come from the untrusted sources, so the huge array sizes are rejected
+// instead of allocating the values that can't fit into any real call data.
+const maxStaticSize = 1 << 24
+
+// NewType creates a new reflection type of abi type given in typeString,
+// e.g. "uint8", "int", "bytes32", "address[]" or "(uint256,bool)[3]".
+// Non-canonical aliases such as "uint" or "int" are converted to their
+// canonical representation ("uint256" and "int256").
+func NewType(typeString string) (Type, error) {
+	if strings.Count(typeString, "[") != strings.Count(typeString, "]") {
+		return Type{}, fmt.Errorf("abi: invalid arg type %q: unbalanced brackets", typeString)
+	}
+	if strings.Count(typeString, "(") != strings.Count(typeString, ")") {
+		return Type{}, fmt.Errorf("abi: invalid arg type %q: unbalanced parentheses", typeString)
+	}
+
+	// if there is a trailing bracket, get ready to go into slice/array mode
+	// and recursively create the type
+	if strings.HasSuffix(typeString, "]") {
+		i := strings.LastIndex(typeString, "[")
+		embeddedType, err := NewType(typeString[:i])
+		if err != nil {
+			return Type{}, err
+		}
+		return newArrayType(embeddedType, typeString[i+1:len(typeString)-1])
+	}
+
+	if strings.HasPrefix(typeString, "(") && strings.HasSuffix(typeString, ")") {
+		components, err := splitTupleComponents(typeString[1 : len(typeString)-1])
+		if err != nil {
+			return Type{}, fmt.Errorf("abi: invalid tuple type %q: %v", typeString, err)
+		}
+		elems := make([]*Type, len(components))
+		for i, component := range components {
+			elem, err := NewType(component)
+			if err != nil {
+				return Type{}, err
+			}
+			elems[i] = &elem
+		}
+		// inline tuples don't have field names
+		return newTupleType(elems, make([]string, len(elems)))
+	}
+
+	return newElementaryType(typeString)
+}
+
+// newTypeWithComponents creates a new reflection type of abi type given in t
+// using the JSON ABI components for tuples, e.g. "tuple[]" or "uint256".
+// The internalType is optional, it's used only for obtaining the raw tuple name.
+func newTypeWithComponents(t string, internalType string, components []ArgumentMarshaling) (Type, error) {
+	if !strings.HasPrefix(t, "tuple") {
+		return NewType(t)
+	}
+	if strings.HasSuffix(t, "]") {
+		// Note internalType can be empty here.
+		subInternal := internalType
+		if i := strings.LastIndex(internalType, "["); i != -1 {
+			subInternal = subInternal[:i]
+		}
+		i := strings.LastIndex(t, "[")
+		embeddedType, err := newTypeWithComponents(t[:i], subInternal, components)
+		if err != nil {
+			return Type{}, err
+		}
+		return newArrayType(embeddedType, t[i+1:len(t)-1])
+	}
+	if t != "tuple" {
+		return Type{}, fmt.Errorf("abi: unsupported arg type: %s", t)
+	}
+	var (
+		elems    = make([]*Type, len(components))
+		rawNames = make([]string, len(components))
+	)
+	for i, c := range components {
+		elem, err := newTypeWithComponents(c.Type, c.InternalType, c.Components)
+		if err != nil {
+			return Type{}, err
+		}
+		elems[i] = &elem
+		rawNames[i] = c.Name
+	}
+	typ, err := newTupleType(elems, rawNames)
+	if err != nil {
+		return Type{}, err
+	}
+	const structPrefix = "struct "
+	// After solidity 0.5.10, a new field of abi "internalType"
+	// is introduced. From that we can obtain the struct name
+	// user defined in the source code.
+	if strings.HasPrefix(internalType, structPrefix) {
+		// Foo.Bar type definition is not allowed in golang,
+		// convert the format to FooBar
+		typ.TupleRawName = strings.Replace(internalType[len(structPrefix):], ".", "", -1)
+	}
+	return typ, nil
+}
+
+// newArrayType creates slice type if size is empty and array type otherwise.
+func newArrayType(elem Type, size string) (Type, error) {
+	if size == "" {
+		return Type{
+			Elem:       &elem,
+			T:          SliceTy,
+			stringKind: elem.stringKind + "[]",
+		}, nil
+	}
+	for _, c := range size {
+		if c < '0' || c > '9' {
+			return Type{}, fmt.Errorf("abi: invalid array size %q", size)
+		}
+	}
+	length, err := strconv.Atoi(size)
+	if err != nil {
+		return Type{}, fmt.Errorf("abi: error parsing array size: %v", err)
+	}
+	if length == 0 {
+		return Type{}, fmt.Errorf("abi: zero array size of %s", elem.stringKind)
+	}
+	// dynamic elements occupy the 32 bytes offset slot each
+	if elemSize := getTypeSize(elem); elemSize == 0 || length > maxStaticSize/elemSize {
+		return Type{}, fmt.Errorf("abi: array size %d of %s is too large", length, elem.stringKind)
+	}
+	return Type{
+		Elem:       &elem,
+		Size:       length,
+		T:          ArrayTy,
+		stringKind: fmt.Sprintf("%s[%d]", elem.stringKind, length),
+	}, nil
+}
+
+// newTupleType creates tuple type from the tuple fields types and their raw names.
+// Empty raw names are allowed, in that case positional go field names are used.
+func newTupleType(elems []*Type, rawNames []string) (Type, error) {
+	if len(elems) == 0 {
+		return Type{}, fmt.Errorf("abi: empty tuple type is not supported")
+	}
+	var (
+		fields     = make([]reflect.StructField, len(elems))
+		fieldNames = make(map[string]struct{}, len(elems))
+		kinds      = make([]string, len(elems))
+	)
+	for i, elem := range elems {
+		fieldName := toCamelCase(rawNames[i])
+		if !token.IsIdentifier(fieldName) || !token.IsExported(fieldName) {
+			fieldName = fmt.Sprintf("Field%d", i)
+		}
+		// Handle overloaded field names
+		if _, ok := fieldNames[fieldName]; ok {
+			base := fieldName
+			for idx := 0; ok; idx++ {
+				fieldName = fmt.Sprintf("%s%d", base, idx)
+				_, ok = fieldNames[fieldName]
+			}
+		}
+		fieldNames[fieldName] = struct{}{}
+		fields[i] = reflect.StructField{
+			Name: fieldName, // reflect.StructOf will panic for any unexported field.
+			Type: elem.GetType(),
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", rawNames[i])),
+		}
+		kinds[i] = elem.stringKind
+	}
+	typ := Type{
+		T:             TupleTy,
+		stringKind:    fmt.Sprintf("(%s)", strings.Join(kinds, ",")),
+		TupleElems:    elems,
+		TupleRawNames: rawNames,
+		TupleType:     reflect.StructOf(fields),
+	}
+	if size := getTypeSize(typ); size > maxStaticSize {
+		return Type{}, fmt.Errorf("abi: tuple %s is too large (%d bytes)", typ.stringKind, size)
+	}
+	return typ, nil
+}
+
+// newElementaryType creates non-composite abi type.
+func newElementaryType(typeString string) (Type, error) {
+	matches := elementaryTypeRegexp.FindStringSubmatch(typeString)
+	if len(matches) != 3 {
+		return Type{}, fmt.Errorf("abi: invalid arg type %q", typeString)
+	}
+	name, size := matches[1], matches[2]
+
+	var typ Type
+	switch name {
+	case "int", "uint":
+		bits := 256
+		if size != "" {
+			var err error
+			bits, err = strconv.Atoi(size)
+			if err != nil {
+				return Type{}, fmt.Errorf("abi: error parsing variable size: %v", err)
+			}
+		}
+		if bits <= 0 || bits > 256 || bits%8 != 0 {
+			return Type{}, fmt.Errorf("abi: invalid integer size %d in type %q", bits, typeString)
+		}
+		typ.T = IntTy
+		if name == "uint" {
+			typ.T = UintTy
+		}
+		typ.Size = bits
+		typ.stringKind = fmt.Sprintf("%s%d", name, bits)
+		return typ, nil
+	case "bytes":
+		if size == "" {
+			typ.T = BytesTy
+			typ.stringKind = name
+			return typ, nil
+		}
+		length, err := strconv.Atoi(size)
+		if err != nil {
+			return Type{}, fmt.Errorf("abi: error parsing variable size: %v", err)
+		}
+		if length <= 0 || length > 32 {
+			return Type{}, fmt.Errorf("abi: invalid fixed bytes size %d in type %q", length, typeString)
+		}
+		typ.T = FixedBytesTy
+		typ.Size = length
+		typ.stringKind = fmt.Sprintf("%s%d", name, length)
+		return typ, nil
+	}
+
+	if size != "" {
+		return Type{}, fmt.Errorf("abi: unsupported arg type: %s", typeString)
+	}
+	switch name {
+	case "bool":
+		typ.T = BoolTy
+	case "string":
+		typ.T = StringTy
+	case "address":
+		typ.T = AddressTy
+		typ.Size = 20
+	case "tuple":
+		return Type{}, fmt.Errorf("abi: tuple type requires components")
+	default:
+		return Type{}, fmt.Errorf("abi: unsupported arg type: %s", typeString)
+	}
+	typ.stringKind = name
+	return typ, nil
+}
+
+// mustNewType is like NewType but panics if the type can't be parsed.
+// It simplifies safe initialization of global variables.
+func mustNewType(typeString string) Type {
+	typ, err := NewType(typeString)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// mustNewTypeWithComponents is like mustNewType but for the tuple types with the given components.
+func mustNewTypeWithComponents(typeString string, components []ArgumentMarshaling) Type {
+	typ, err := newTypeWithComponents(typeString, "", components)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// splitTupleComponents splits the tuple components by the top-level commas.
+func splitTupleComponents(components string) ([]string, error) {
+	var (
+		result []string
+		depth  int
+		begin  int
+	)
+	for i, c := range components {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected ')' at position %d", i)
+			}
+		case ',':
+			if depth == 0 {
+				result = append(result, components[begin:i])
+				begin = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	result = append(result, components[begin:])
+	for i := range result {
+		result[i] = strings.TrimSpace(result[i])
+		if result[i] == "" {
+			return nil, fmt.Errorf("empty component at position %d", i)
+		}
+	}
+	return result, nil
+}
+
+// toCamelCase converts an under-score string to a camel-case string
+func toCamelCase(input string) string {
+	parts := strings.Split(input, "_")
+	for i, s := range parts {
+		if len(s) > 0 {
+			parts[i] = strings.ToUpper(s[:1]) + s[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// String implements Stringer.
+func (t Type) String() string {
 	return t.stringKind
 }
 
@@ -61,7 +382,13 @@ func requiresLengthPrefix(t Type) bool {
 // For a dynamic variable, the returned size is fixed 32 bytes, which is used
 // to store the location reference for actual value storage.
 func getTypeSize(t Type) int {
-	if t.T == TupleTy && !isDynamicType(t) {
+	if t.T == ArrayTy && !isDynamicType(*t.Elem) {
+		// Recursively calculate type size if it is a nested array
+		if t.Elem.T == ArrayTy || t.Elem.T == TupleTy {
+			return t.Size * getTypeSize(*t.Elem)
+		}
+		return t.Size * 32
+	} else if t.T == TupleTy && !isDynamicType(t) {
 		// Recursively calculate type size if it is a nested tuple
 		total := 0
 		for _, elem := range t.TupleElems {
@@ -85,13 +412,18 @@ func (t Type) GetType() reflect.Type {
 		return reflect.TypeOf("")
 	case SliceTy:
 		return reflect.SliceOf(t.Elem.GetType())
+	case ArrayTy:
+		return reflect.ArrayOf(t.Size, t.Elem.GetType())
 	case TupleTy:
 		return t.TupleType
 	case AddressTy:
-		// TODO(nickeskov): use our address
-		return reflect.TypeOf(common.Address{})
+		return reflect.TypeOf(Address{})
+	case FixedBytesTy:
+		return reflect.ArrayOf(t.Size, reflect.TypeOf(byte(0)))
 	case BytesTy:
 		return reflect.SliceOf(reflect.TypeOf(byte(0)))
+	case HashTy:
+		return reflect.TypeOf(Hash{})
 	default:
 		panic(fmt.Errorf("invalid ABI type (T=%d)", t.T))
 	}
@@ -113,5 +445,5 @@ func isDynamicType(t Type) bool {
 		}
 		return false
 	}
-	return t.T == StringTy || t.T == BytesTy || t.T == SliceTy
+	return t.T == StringTy || t.T == BytesTy || t.T == SliceTy || (t.T == ArrayTy && isDynamicType(*t.Elem))
 }
@@ -0,0 +1,110 @@
+package fourbyte
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestNewType(t *testing.T) {
+	tests := []struct {
+		typeString string
+		stringKind string
+		argT       ArgT
+		size       int
+		goType     reflect.Type
+	}{
+		{"uint8", "uint8", UintTy, 8, reflect.TypeOf(uint8(0))},
+		{"uint", "uint256", UintTy, 256, reflect.TypeOf(&big.Int{})},
+		{"int", "int256", IntTy, 256, reflect.TypeOf(&big.Int{})},
+		{"int64", "int64", IntTy, 64, reflect.TypeOf(int64(0))},
+		{"bool", "bool", BoolTy, 0, reflect.TypeOf(false)},
+		{"string", "string", StringTy, 0, reflect.TypeOf("")},
+		{"bytes", "bytes", BytesTy, 0, reflect.TypeOf([]byte{})},
+		{"bytes32", "bytes32", FixedBytesTy, 32, reflect.TypeOf([32]byte{})},
+		{"address", "address", AddressTy, 20, reflect.TypeOf(Address{})},
+		{"address[]", "address[]", SliceTy, 0, reflect.TypeOf([]Address{})},
+		{"uint[2][]", "uint256[2][]", SliceTy, 0, reflect.TypeOf([][2]*big.Int{})},
+		{"bytes4[3]", "bytes4[3]", ArrayTy, 3, reflect.TypeOf([3][4]byte{})},
+	}
+	for _, tc := range tests {
+		typ, err := NewType(tc.typeString)
+		require.NoError(t, err, tc.typeString)
+		require.Equal(t, tc.stringKind, typ.String(), tc.typeString)
+		require.Equal(t, tc.argT, typ.T, tc.typeString)
+		require.Equal(t, tc.size, typ.Size, tc.typeString)
+		require.Equal(t, tc.goType, typ.GetType(), tc.typeString)
+	}
+}
+
+func TestNewTupleType(t *testing.T) {
+	typ, err := NewType("(uint256,(bool,string[]),address)[3]")
+	require.NoError(t, err)
+	require.Equal(t, "(uint256,(bool,string[]),address)[3]", typ.String())
+	require.Equal(t, ArrayTy, typ.T)
+	require.Equal(t, 3, typ.Size)
+
+	tuple := typ.Elem
+	require.Equal(t, TupleTy, tuple.T)
+	require.Len(t, tuple.TupleElems, 3)
+	require.Len(t, tuple.TupleRawNames, 3)
+	require.Equal(t, "(bool,string[])", tuple.TupleElems[1].String())
+	require.Equal(t, TupleTy, tuple.TupleElems[1].T)
+	require.Equal(t, reflect.Struct, tuple.TupleType.Kind())
+	require.Equal(t, 3, tuple.TupleType.NumField())
+	require.Equal(t, reflect.TypeOf([]string{}), tuple.TupleType.Field(1).Type.Field(1).Type)
+}
+
+func TestNewTypeInvalid(t *testing.T) {
+	for _, typeString := range []string{
+		"",
+		"uint7",
+		"uint264",
+		"int0",
+		"bytes0",
+		"bytes33",
+		"address20",
+		"bool[",
+		"bool[-1]",
+		"bool[x]",
+		"tuple",
+		"()",
+		"(uint256,)",
+		"(uint256))(",
+		"fixed128x18",
+		"function",
+		"Uint256",
+		"uint256[9223372036854775807]",
+		"uint256[524289]",
+		"string[524289]",
+		"uint8[4096][4097]",
+		"(uint256[524288],bool)",
+		"uint256[0]",
+		"uint256[0][2]",
+		"(uint256[0])[2]",
+		"bool[00]",
+	} {
+		_, err := NewType(typeString)
+		require.Error(t, err, typeString)
+	}
+}
+
+func TestArgTValues(t *testing.T) {
+	// the values are exposed through InternalType, so the new kinds must not renumber the existing ones
+	for argT, value := range map[ArgT]byte{
+		IntTy:        0,
+		UintTy:       1,
+		BoolTy:       2,
+		StringTy:     3,
+		SliceTy:      4,
+		BytesTy:      5,
+		TupleTy:      6,
+		AddressTy:    7,
+		ArrayTy:      8,
+		FixedBytesTy: 9,
+		HashTy:       10,
+	} {
+		require.Equal(t, value, byte(argT))
+	}
+}
@@ -9,7 +9,8 @@ import (
 )
 
 var (
-	errBadBool = errors.New("abi: improperly encoded boolean value")
+	errBadBool       = errors.New("abi: improperly encoded boolean value")
+	errBadFixedBytes = errors.New("abi: improperly encoded fixed bytes value")
 )
 
 // readBool reads a bool.
@@ -70,28 +71,52 @@ func ReadInteger(typ Type, b []byte) interface{} {
 	}
 }
 
+// ReadFixedBytes uses reflection to create a fixed array to be read from.
+// Fixed bytes are padded with zeros on the right side, non-zero padding is treated as an error.
+func ReadFixedBytes(t Type, word []byte) (interface{}, error) {
+	if t.T != FixedBytesTy {
+		return nil, fmt.Errorf("abi: invalid type in call to make fixed byte array")
+	}
+	for _, b := range word[t.Size:] {
+		if b != 0 {
+			return nil, errBadFixedBytes
+		}
+	}
+	// convert
+	array := reflect.New(t.GetType()).Elem()
+	reflect.Copy(array, reflect.ValueOf(word[0:t.Size]))
+	return array.Interface(), nil
+}
+
 // forEachUnpack iteratively unpack elements.
 func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error) {
 	if size < 0 {
 		return nil, fmt.Errorf("cannot marshal input to array, size is negative (%d)", size)
 	}
-	if start+32*size > len(output) {
+	// Arrays have packed elements, resulting in longer unpack steps.
+	// Slices have just 32 bytes per element (pointing to the contents).
+	elemSize := getTypeSize(*t.Elem)
+	if elemSize == 0 {
+		return nil, fmt.Errorf("abi: cannot unpack zero-size elements of %s", t.stringKind)
+	}
+	// the size is checked by division, so the huge sizes can't overflow
+	if start > len(output) || size > (len(output)-start)/elemSize {
 		return nil, fmt.Errorf(
-			"abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)",
-			len(output), start+32*size,
+			"abi: cannot marshal in to go array: %d elements of %d bytes at offset %d would go over slice boundary (len=%d)",
+			size, elemSize, start, len(output),
 		)
 	}
-	if t.T != SliceTy {
-		return nil, fmt.Errorf("abi: invalid type in slice unpacking stage")
-
-	}
 
 	// this value will become our slice or our array, depending on the type
-	refSlice := reflect.MakeSlice(t.GetType(), size, size)
-
-	// Arrays have packed elements, resulting in longer unpack steps.
-	// Slices have just 32 bytes per element (pointing to the contents).
-	elemSize := getTypeSize(*t.Elem)
+	var refSlice reflect.Value
+	switch t.T {
+	case SliceTy:
+		refSlice = reflect.MakeSlice(t.GetType(), size, size)
+	case ArrayTy:
+		refSlice = reflect.New(t.GetType()).Elem()
+	default:
+		return nil, fmt.Errorf("abi: invalid type in array/slice unpacking stage")
+	}
 
 	for i, j := start, 0; j < size; i, j = i+elemSize, j+1 {
 		inter, err := toGoType(i, *t.Elem, output)
@@ -112,7 +137,13 @@ func forTupleUnpack(t Type, output []byte) (interface{}, error) {
 	virtualArgs := 0
 	for index, elem := range t.TupleElems {
 		marshalledValue, err := toGoType((index+virtualArgs)*32, *elem, output)
-		if elem.T == TupleTy && !isDynamicType(*elem) {
+		if elem.T == ArrayTy && !isDynamicType(*elem) {
+			// If we have a static array, like [3]uint256, these are coded as
+			// just like uint256,uint256,uint256.
+			// This means that we need to add two 'virtual' arguments when
+			// we count the index from now on.
+			virtualArgs += getTypeSize(*elem)/32 - 1
+		} else if elem.T == TupleTy && !isDynamicType(*elem) {
 			// If we have a static tuple, like (uint256, bool, uint256), these are
 			// coded as just like uint256,bool,uint256
 			virtualArgs += getTypeSize(*elem)/32 - 1
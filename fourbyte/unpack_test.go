@@ -0,0 +1,137 @@
+package fourbyte
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+// ethPack packs values with go-ethereum abi package, which is used as reference implementation.
+func ethPack(t *testing.T, types []string, values ...interface{}) []byte {
+	args := make(abi.Arguments, len(types))
+	for i, typeString := range types {
+		typ, err := abi.NewType(typeString, "", nil)
+		require.NoError(t, err)
+		args[i] = abi.Argument{Type: typ}
+	}
+	data, err := args.PackValues(values)
+	require.NoError(t, err)
+	return data
+}
+
+func newTestArguments(t *testing.T, types ...string) Arguments {
+	args := make(Arguments, len(types))
+	for i, typeString := range types {
+		typ, err := NewType(typeString)
+		require.NoError(t, err)
+		args[i] = Argument{Type: typ}
+	}
+	return args
+}
+
+func TestUnpackFixedArrays(t *testing.T) {
+	addresses := [3]common.Address{
+		common.HexToAddress("0x9a1989946ae4249aac19ac7a038d24aab03c3d8c"),
+		common.HexToAddress("0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c"),
+		common.HexToAddress("0x0000000000000000000000000000000000000001"),
+	}
+	types := []string{"uint256[2]", "address[3]", "uint8[2][3]", "string[2]", "uint64[2][]", "bool"}
+	data := ethPack(t, types,
+		[2]*big.Int{big.NewInt(1), big.NewInt(2)},
+		addresses,
+		[3][2]uint8{{1, 2}, {3, 4}, {5, 6}},
+		[2]string{"hello", "world"},
+		[][2]uint64{{7, 8}, {9, 10}},
+		true,
+	)
+
+	values, err := newTestArguments(t, types...).UnpackValues(data)
+	require.NoError(t, err)
+	require.Len(t, values, len(types))
+	require.Equal(t, [2]*big.Int{big.NewInt(1), big.NewInt(2)}, values[0])
+	require.Equal(t, [3]Address{Address(addresses[0]), Address(addresses[1]), Address(addresses[2])}, values[1])
+	require.Equal(t, [3][2]uint8{{1, 2}, {3, 4}, {5, 6}}, values[2])
+	require.Equal(t, [2]string{"hello", "world"}, values[3])
+	require.Equal(t, [][2]uint64{{7, 8}, {9, 10}}, values[4])
+	require.Equal(t, true, values[5])
+}
+
+func TestUnpackTupleWithFixedArray(t *testing.T) {
+	type tuple struct {
+		A [2]uint32
+		B string
+	}
+	ethTuple, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "a", Type: "uint32[2]"},
+		{Name: "b", Type: "string"},
+	})
+	require.NoError(t, err)
+	data, err := abi.Arguments{{Type: ethTuple}}.Pack(tuple{A: [2]uint32{1, 2}, B: "abc"})
+	require.NoError(t, err)
+
+	values, err := newTestArguments(t, "(uint32[2],string)").UnpackValues(data)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	require.Equal(t, "{[1 2] abc}", fmt.Sprintf("%v", values[0]))
+}
+
+func TestUnpackFixedBytes(t *testing.T) {
+	types := []string{"bytes4", "bytes32", "bytes2[2]"}
+	data := ethPack(t, types,
+		[4]byte{0xa9, 0x05, 0x9c, 0xbb},
+		[32]byte{31: 0xff},
+		[2][2]byte{{1, 2}, {3, 4}},
+	)
+	values, err := newTestArguments(t, types...).UnpackValues(data)
+	require.NoError(t, err)
+	require.Equal(t, [4]byte{0xa9, 0x05, 0x9c, 0xbb}, values[0])
+	require.Equal(t, [32]byte{31: 0xff}, values[1])
+	require.Equal(t, [2][2]byte{{1, 2}, {3, 4}}, values[2])
+
+	// non-zero right padding must be rejected
+	data[4] = 1
+	_, err = newTestArguments(t, types...).UnpackValues(data)
+	require.Error(t, err)
+
+	hash, err := toGoType(0, Type{T: HashTy, Size: 32}, data[32:64])
+	require.NoError(t, err)
+	require.Equal(t, Hash{31: 0xff}, hash)
+}
+
+func TestUnpackHugeArray(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	require.Error(t, db.AddSelector("g(uint256[9223372036854775807])"))
+
+	// types built by hand bypass the NewType limits, the unpacker must not panic on them
+	elem := mustNewType("uint256")
+	args := Arguments{{Type: Type{Elem: &elem, Size: math.MaxInt64, T: ArrayTy}}}
+	_, err = args.UnpackValues(make([]byte, 64))
+	require.Error(t, err)
+
+	_, err = newTestArguments(t, "uint256[3]").UnpackValues(make([]byte, 64))
+	require.Error(t, err)
+}
+
+func TestUnpackZeroSizeArray(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	require.Error(t, db.AddSelector("f(uint256[0][2])"))
+	require.Error(t, db.AddSelector("g(uint256[0],uint256)"))
+	path := filepath.Join(t.TempDir(), "4byte_custom.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"00000000": "f((uint256[0])[2])"}`), 0600))
+	require.Error(t, db.LoadCustom(path))
+
+	// types built by hand bypass the NewType checks, the unpacker must not divide by zero on them
+	elem := mustNewType("uint256")
+	empty := Type{Elem: &elem, T: ArrayTy}
+	args := Arguments{{Type: Type{Elem: &empty, Size: 2, T: ArrayTy}}}
+	_, err = args.UnpackValues(make([]byte, 64))
+	require.Error(t, err)
+}
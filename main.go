@@ -3,28 +3,38 @@ package main
 import (
 	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"github.com/abi_eth/fourbyte"
+	"io"
+	"io/ioutil"
+	"os"
 	"regexp"
 	"strings"
 )
 
+// Exit codes of abidump.
+const (
+	exitOK           = 0 // all the call data is decoded
+	exitDecodeFailed = 1 // some call data is invalid hex or can't be decoded
+	exitUsage        = 2 // invalid flags or input
+	exitIO           = 3 // writing the output, reading the batch input or serving failed
+)
+
 func parse(data []byte) (*fourbyte.DecodedCallData, error) {
 	db, err := fourbyte.NewDatabase()
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	decodedData, err := db.ParseCallData(data)
-	return decodedData, err
+	return db.ParseCallData(data)
 }
 
 func parseNew(data []byte) (*fourbyte.DecodedCallData, error) {
 	db, err := fourbyte.NewDatabase()
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	decodedData, err := db.ParseCallDataNew(data)
-	return decodedData, err
+	return db.ParseCallDataNew(data)
 }
 
 var selectorRegexp = regexp.MustCompile(`^([^\)]+)\(([A-Za-z0-9,\[\]]*)\)`)
@@ -57,31 +67,189 @@ func getJsonAbi(selector string) ([]byte, error) {
 	return json.Marshal([]ABI{{name, "function", arguments}})
 }
 
-// Example
-// ./abidump a9059cbb000000000000000000000000ea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c0000000000000000000000000000000000000000000000015af1d78b58c40000
-func main() {
+// stringsFlag is the flag which may be repeated, e.g. -signatures a.json -signatures b.json
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
 
-	hexdata := "a9059cbb000000000000000000000000ea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c0000000000000000000000000000000000000000000000015af1d78b58c40000"
-	data, err := hex.DecodeString(strings.TrimPrefix(hexdata, "0x"))
+const usage = `Usage: abidump [flags] [hexdata...]
+       abidump batch [flags] [file]
+       abidump serve [flags]
+
+Decodes the hex encoded call data, e.g.
+  abidump a9059cbb000000000000000000000000ea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c0000000000000000000000000000000000000000000000015af1d78b58c40000
+
+The call data is taken from the arguments, the -file flag or stdin.
+
+Flags:
+`
+
+// run executes abidump with the command line arguments and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 0 && args[0] == "batch" {
+		return runBatch(args[1:], stdin, stdout, stderr)
+	}
+	if len(args) != 0 && args[0] == "serve" {
+		return runServe(args[1:], stderr)
+	}
+	flags := flag.NewFlagSet("abidump", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	var (
+		decoder    = flags.String("decoder", "native", "call data decoder: native or legacy")
+		format     = flags.String("format", "text", "output format: text or json")
+		file       = flags.String("file", "", `read the call data from the file instead of the arguments, one per line, "-" for stdin`)
+		signatures stringsFlag
+	)
+	flags.Var(&signatures, "signatures", "additional signatures file in the 4byte.json format, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return exitUsage
+	}
+
+	db, err := newDatabase(signatures)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var parseCallData func(data []byte) (*fourbyte.DecodedCallData, error)
+	switch *decoder {
+	case "native":
+		parseCallData = db.ParseCallDataNew
+	case "legacy":
+		parseCallData = db.ParseCallData
+	default:
+		fmt.Fprintf(stderr, "unknown decoder %q\n", *decoder)
+		return exitUsage
+	}
+
+	inputs, err := readInputs(flags.Args(), *file, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	code := exitOK
+	for _, input := range inputs {
+		data, err := decodeHex(input)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid hex data %q: %v\n", input, err)
+			code = exitDecodeFailed
+			continue
+		}
+		decoded, err := parseCallData(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to decode %s: %v\n", input, err)
+			code = exitDecodeFailed
+			continue
+		}
+		if err := writeCallData(stdout, *format, decoded); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitIO
+		}
+	}
+	return code
+}
+
+// decodeHex decodes the hex string with the optional 0x prefix.
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+// readInputs returns the hex call data from the arguments, the file or stdin.
+// The file and stdin may hold several call data separated by whitespace or newlines.
+func readInputs(args []string, file string, stdin io.Reader) ([]string, error) {
+	if len(args) != 0 {
+		if file != "" {
+			return nil, fmt.Errorf("call data can't be taken from both the arguments and the file")
+		}
+		return args, nil
+	}
+	var (
+		blob []byte
+		err  error
+	)
+	if file == "" || file == "-" {
+		blob, err = ioutil.ReadAll(stdin)
+	} else {
+		blob, err = ioutil.ReadFile(file)
 	}
-	decodedData, err := parse(data)
 	if err != nil {
-		fmt.Println(err)
+		return nil, fmt.Errorf("failed to read call data: %v", err)
+	}
+	// one call data per line, blank lines are skipped
+	inputs := strings.Fields(string(blob))
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no call data provided")
 	}
-	fmt.Println(decodedData.Inputs[0].DecodedValue())
-	fmt.Println(decodedData.Inputs[1].DecodedValue())
-	fmt.Println(decodedData)
+	return inputs, nil
+}
 
-	fmt.Printf("\n\n ------------------------ \n\n")
+// newDatabase creates the database with the additional signatures files.
+func newDatabase(signatures []string) (*fourbyte.Database, error) {
+	db, err := fourbyte.NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range signatures {
+		if err := addSignaturesFile(db, path); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
 
-	decodedDataNew, err := parseNew(data)
+func addSignaturesFile(db *fourbyte.Database, path string) error {
+	f, err := os.Open(path)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
-	fmt.Println(decodedDataNew.Inputs[0].DecodedValue())
-	fmt.Println(decodedDataNew.Inputs[1].DecodedValue())
-	fmt.Println(decodedDataNew)
+	defer f.Close()
+	signatures, err := fourbyte.Load4byteJSON(f)
+	if err != nil {
+		return fmt.Errorf("failed to load signatures file %q: %v", path, err)
+	}
+	if err := db.AddSignatures(signatures); err != nil {
+		return fmt.Errorf("invalid signatures file %q: %v", path, err)
+	}
+	return nil
+}
 
+func writeCallData(w io.Writer, format string, decoded *fourbyte.DecodedCallData) error {
+	if format == "json" {
+		return json.NewEncoder(w).Encode(decoded)
+	}
+	if _, err := fmt.Fprintln(w, decoded.Signature); err != nil {
+		return err
+	}
+	for i, input := range decoded.Inputs {
+		if _, err := fmt.Fprintf(w, "  [%d] %s\n", i, input); err != nil {
+			return err
+		}
+	}
+	for _, payment := range decoded.Payments {
+		if _, err := fmt.Fprintf(w, "  payment %s\n", payment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -43,3 +50,190 @@ func TestJsonAbi(t *testing.T) {
 	resJson, err := getJsonAbi(callData.Signature)
 	require.Equal(t, string(resJson), expectedJson)
 }
+
+func TestRun(t *testing.T) {
+	// from https://etherscan.io/tx/0x363f979b58c82614db71229c2a57ed760e7bc454ee29c2f8fd1df99028667ea5
+	hexdata := "0xa9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c000000000000000000000000000000000000000000002c5b68601cc92ad60000"
+	setFee := "0xbb3568250000000000000000000000000000000000000000000000000000000000000001"
+
+	dir := t.TempDir()
+	signaturesPath := filepath.Join(dir, "signatures.json")
+	require.NoError(t, ioutil.WriteFile(signaturesPath, []byte(`{"bb356825": "abidumpSetFee(uint256)"}`), 0600))
+	dataPath := filepath.Join(dir, "calldata.txt")
+	require.NoError(t, ioutil.WriteFile(dataPath, []byte(hexdata+"\n"), 0600))
+	multiPath := filepath.Join(dir, "calldata_lines.txt")
+	require.NoError(t, ioutil.WriteFile(multiPath, []byte(hexdata+"\n\n  "+hexdata+"\r\n0xzz\n"+hexdata+"\n"), 0600))
+	transferOutput := "transfer(address,uint256)\n  [0] address: 0x9a1989946ae4249AAC19ac7a038d24Aab03c3D8c\n  [1] uint256: 209470300000000000000000\n"
+
+	tests := []struct {
+		args   []string
+		stdin  string
+		code   int
+		output string
+	}{
+		{args: []string{hexdata}, code: exitOK, output: "transfer(address,uint256)\n  [0] address: 0x9a1989946ae4249AAC19ac7a038d24Aab03c3D8c\n  [1] uint256: 209470300000000000000000\n"},
+		{args: []string{"-decoder", "legacy", hexdata}, code: exitOK, output: "transfer(address,uint256)\n"},
+		{args: []string{"-format", "json", hexdata}, code: exitOK, output: `{"signature":"transfer(address,uint256)","selector":"0xa9059cbb","name":"transfer",`},
+		{args: []string{"-file", dataPath}, code: exitOK, output: "transfer(address,uint256)\n"},
+		{stdin: hexdata, code: exitOK, output: "transfer(address,uint256)\n"},
+		{args: []string{"-file", multiPath}, code: exitDecodeFailed, output: strings.Repeat(transferOutput, 3)},
+		{stdin: hexdata + "\n" + hexdata + "\n", code: exitOK, output: strings.Repeat(transferOutput, 2)},
+		{args: []string{"-signatures", signaturesPath, setFee}, code: exitOK, output: "abidumpSetFee(uint256)\n"},
+		{args: []string{setFee}, code: exitDecodeFailed},
+		{args: []string{hexdata, setFee}, code: exitDecodeFailed, output: "transfer(address,uint256)\n"},
+		{args: []string{"0xzz"}, code: exitDecodeFailed},
+		{args: []string{"0xzz", hexdata}, code: exitDecodeFailed, output: "transfer(address,uint256)\n"},
+		{args: []string{"-decoder", "unknown", hexdata}, code: exitUsage},
+		{args: []string{"-format", "xml", hexdata}, code: exitUsage},
+		{args: []string{"-signatures", dataPath, hexdata}, code: exitUsage},
+		{stdin: " \n", code: exitUsage},
+	}
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		require.Equal(t, tc.code, code, "%v: %s", tc.args, stderr.String())
+		require.True(t, strings.HasPrefix(stdout.String(), tc.output), "%v: %s", tc.args, stdout.String())
+	}
+
+	// the output failure isn't reported as the invalid input
+	var stderr bytes.Buffer
+	require.Equal(t, exitIO, run([]string{hexdata}, strings.NewReader(""), failingWriter{}, &stderr))
+	require.Equal(t, exitIO, run([]string{"batch"}, strings.NewReader(hexdata), failingWriter{}, &stderr))
+}
+
+func TestRunBatch(t *testing.T) {
+	transfer := "a9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c%064x"
+	var input strings.Builder
+	for i := 0; i < batchWindowSize+10; i++ {
+		switch i % 100 {
+		case 7:
+			input.WriteString("0xzz\n")
+		case 8:
+			input.WriteString(`{"to":"0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c","data":"0x12345678"}` + "\n")
+		case 9:
+			input.WriteString(fmt.Sprintf(`{"input":"0x`+transfer+`"}`+"\n", i))
+		default:
+			input.WriteString(fmt.Sprintf(transfer+"\n", i))
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"batch", "-workers", "4"}, strings.NewReader(input.String()), &stdout, &stderr)
+	require.Equal(t, exitDecodeFailed, code, stderr.String())
+
+	decoder := json.NewDecoder(&stdout)
+	for i := 0; i < batchWindowSize+10; i++ {
+		var output batchOutput
+		require.NoError(t, decoder.Decode(&output))
+		require.Equal(t, i+1, output.Line)
+		if i%100 == 7 || i%100 == 8 {
+			require.NotEmpty(t, output.Error, i)
+			continue
+		}
+		require.Empty(t, output.Error, i)
+		require.Equal(t, int64(i), output.Result.Inputs[1].DecodedValue().(*big.Int).Int64())
+	}
+	require.False(t, decoder.More())
+
+	stdout.Reset()
+	code = run([]string{"batch"}, strings.NewReader(fmt.Sprintf(transfer+"\n\n", 1)), &stdout, &stderr)
+	require.Equal(t, exitOK, code)
+	require.Equal(t, 1, strings.Count(stdout.String(), "\n"))
+	require.Equal(t, exitUsage, run([]string{"batch", "-workers", "0"}, strings.NewReader(""), &stdout, &stderr))
+
+	// the write error stops the reading of the rest records
+	db, err := newDatabase(nil)
+	require.NoError(t, err)
+	_, err = decodeBatch(db, strings.NewReader(input.String()), failingWriter{}, 4)
+	require.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestServe(t *testing.T) {
+	db, err := newDatabase(nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(newServer(db))
+	defer server.Close()
+
+	post := func(path, body string) (int, map[string]interface{}) {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	transfer := "0xa9059cbb0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c00000000000000000000000000000000000000000000000000000000000003e8"
+	code, result := post("/decode/calldata", `{"data":"`+transfer+`"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "transfer(address,uint256)", result["signature"])
+	require.Equal(t, "0xa9059cbb", result["selector"])
+
+	code, result = post("/decode/calldata", `{"to":"0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c","data":"0x12345678"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	require.NotEmpty(t, result["error"])
+
+	code, _ = post("/decode/calldata", `{"data":"0xzz"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = post("/decode/calldata", `{"to":"0x01","data":"0x"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, result = post("/decode/log", `{
+		"address": "0xea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c",
+		"topics": [
+			"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			"0x000000000000000000000000ea0e2dc7d65a50e77fc7e84bff3fd2a9e781ff5c",
+			"0x0000000000000000000000009a1989946ae4249aac19ac7a038d24aab03c3d8c"
+		],
+		"data": "0x00000000000000000000000000000000000000000000000000000000000003e8"
+	}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "Transfer", result["name"])
+	require.Len(t, result["inputs"], 3)
+
+	code, _ = post("/decode/log", `{"topics":["0xdd"],"data":"0x"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	revert := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6e6f706500000000000000000000000000000000000000000000000000000000"
+	code, result = post("/decode/revert", `{"data":"`+revert+`"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "Error", result["name"])
+	require.Equal(t, "nope", result["message"])
+
+	code, _ = post("/decode/revert", `{"data":"0x"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+
+	resp, err := http.Get(server.URL + "/decode/calldata")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/selector/0xa9059cbb")
+	require.NoError(t, err)
+	var selector selectorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&selector))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "0xa9059cbb", selector.Selector)
+	require.Contains(t, selector.Signatures, "transfer(address,uint256)")
+
+	for path, status := range map[string]int{
+		"/selector/a9059c":   http.StatusBadRequest,
+		"/selector/00000000": http.StatusNotFound,
+	} {
+		resp, err = http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/abi_eth/fourbyte"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// serverMaxBodySize is the maximum size of the decode request body.
+const serverMaxBodySize = 16 << 20
+
+const serveUsage = `Usage: abidump serve [flags]
+
+Serves the decoding HTTP API:
+  POST /decode/calldata  {"to": "0x...", "data": "0x..."}, "to" is optional
+  POST /decode/log       {"address": "0x...", "topics": ["0x..."], "data": "0x..."}, "address" is optional
+  POST /decode/revert    {"to": "0x...", "data": "0x..."}, "to" is optional
+  GET  /selector/{hex}   candidate signatures of the 4-byte selector
+
+Flags:
+`
+
+type calldataRequest struct {
+	To   *fourbyte.Address `json:"to"`
+	Data string            `json:"data"`
+}
+
+type logRequest struct {
+	Address *fourbyte.Address `json:"address"`
+	Topics  []string          `json:"topics"`
+	Data    string            `json:"data"`
+}
+
+type selectorResponse struct {
+	Selector   string   `json:"selector"`
+	Signatures []string `json:"signatures"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// runServe executes the serve subcommand and returns the exit code.
+func runServe(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("abidump serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, serveUsage)
+		flags.PrintDefaults()
+	}
+	var (
+		addr       = flags.String("addr", "127.0.0.1:8080", "address to listen on")
+		custom     = flags.String("custom", "", "custom signatures file in the 4byte.json format, reloaded when it changes")
+		reload     = flags.Duration("reload", 5*time.Second, "interval of the custom signatures file change checks")
+		signatures stringsFlag
+	)
+	flags.Var(&signatures, "signatures", "additional signatures file in the 4byte.json format, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	db, err := newDatabase(signatures)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *custom != "" {
+		err := db.WatchCustom(ctx, *custom, *reload, func(err error) {
+			fmt.Fprintln(stderr, err)
+		})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	server := &http.Server{Addr: *addr, Handler: newServer(db), ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+	}()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		fmt.Fprintln(stderr, err)
+		return exitIO
+	}
+	<-done
+	return exitOK
+}
+
+// newServer creates the HTTP handler of the decoding API backed by the shared database.
+func newServer(db *fourbyte.Database) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/decode/calldata", func(w http.ResponseWriter, r *http.Request) {
+		var req calldataRequest
+		data, ok := readDecodeRequest(w, r, &req, &req.Data)
+		if !ok {
+			return
+		}
+		var (
+			decoded *fourbyte.DecodedCallData
+			err     error
+		)
+		if req.To != nil {
+			decoded, err = db.ParseContractCallDataNew(*req.To, data)
+		} else {
+			decoded, err = db.ParseCallDataNew(data)
+		}
+		writeDecodeResult(w, decoded, err)
+	})
+	mux.HandleFunc("/decode/log", func(w http.ResponseWriter, r *http.Request) {
+		var req logRequest
+		data, ok := readDecodeRequest(w, r, &req, &req.Data)
+		if !ok {
+			return
+		}
+		topics := make([][32]byte, len(req.Topics))
+		for i, topic := range req.Topics {
+			bts, err := decodeHex(topic)
+			if err != nil || len(bts) != len(topics[i]) {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid topic %q", topic)})
+				return
+			}
+			copy(topics[i][:], bts)
+		}
+		var (
+			decoded *fourbyte.DecodedLog
+			err     error
+		)
+		if req.Address != nil {
+			decoded, err = db.DecodeContractLog(*req.Address, topics, data)
+		} else {
+			decoded, err = db.DecodeLog(topics, data)
+		}
+		writeDecodeResult(w, decoded, err)
+	})
+	mux.HandleFunc("/decode/revert", func(w http.ResponseWriter, r *http.Request) {
+		var req calldataRequest
+		data, ok := readDecodeRequest(w, r, &req, &req.Data)
+		if !ok {
+			return
+		}
+		var (
+			decoded *fourbyte.DecodedRevert
+			err     error
+		)
+		if req.To != nil {
+			decoded, err = db.DecodeContractRevert(*req.To, data)
+		} else {
+			decoded, err = db.DecodeRevert(data)
+		}
+		writeDecodeResult(w, decoded, err)
+	})
+	mux.HandleFunc("/selector/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+		hexSelector := strings.TrimPrefix(r.URL.Path, "/selector/")
+		selector, err := decodeHex(hexSelector)
+		if err != nil || len(selector) != 4 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid selector %q", hexSelector)})
+			return
+		}
+		signatures, err := db.Selectors(selector)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, selectorResponse{Selector: fmt.Sprintf("0x%x", selector), Signatures: signatures})
+	})
+	return mux
+}
+
+// readDecodeRequest reads the JSON request of the decode endpoint and decodes its hex data field,
+// the error response is written if the request is invalid.
+func readDecodeRequest(w http.ResponseWriter, r *http.Request, req interface{}, hexData *string) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return nil, false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, serverMaxBodySize)).Decode(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return nil, false
+	}
+	data, err := decodeHex(*hexData)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid hex data: %v", err)})
+		return nil, false
+	}
+	return data, true
+}
+
+// writeDecodeResult writes the decoded value or the decoding error.
+func writeDecodeResult(w http.ResponseWriter, decoded interface{}, err error) {
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, decoded)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// the status is already sent, so the encoding error can't be reported to the client
+	_ = json.NewEncoder(w).Encode(value)
+}